@@ -8,6 +8,7 @@ import (
 )
 
 type Result struct {
+	RunID            string
 	StartedAt        time.Time
 	CompletedAt      time.Time
 	FilesTotal       int
@@ -15,7 +16,58 @@ type Result struct {
 	BytesTotal       int64
 	BytesTransferred int64
 	Errors           []string
-	Success          bool
+	// Warnings are errors the job's warnings rules expect; they don't
+	// make the run fail.
+	Warnings []string
+	// TypedErrors groups the errors and warnings by recognised cause.
+	TypedErrors []config.RunError
+	Success     bool
+	AbortReason string
+	// ExitCode is the first non-zero rsync exit code among the sources.
+	ExitCode     int
+	RsyncVersion string
+	Sources      []SourceResult
+}
+
+// SourceResult holds the outcome of transferring one of a job's sources.
+// The totals on Result are the sums over all sources.
+type SourceResult struct {
+	Path             string
+	FilesTotal       int
+	FilesTransferred int
+	BytesTotal       int64
+	BytesTransferred int64
+	Errors           []string
+	Warnings         []string
+	TypedErrors      []config.RunError
+	ExitCode         int
+}
+
+// Outcome classifies the run as success, warning or failure.
+func (r *Result) Outcome() string {
+	switch {
+	case !r.Success:
+		return config.OutcomeFailure
+	case len(r.Warnings) > 0:
+		return config.OutcomeWarning
+	default:
+		return config.OutcomeSuccess
+	}
+}
+
+// addSource records a finished source and adds it to the run's totals.
+func (r *Result) addSource(src SourceResult) {
+	r.Sources = append(r.Sources, src)
+	r.FilesTotal += src.FilesTotal
+	r.FilesTransferred += src.FilesTransferred
+	r.BytesTotal += src.BytesTotal
+	r.BytesTransferred += src.BytesTransferred
+	r.Errors = append(r.Errors, src.Errors...)
+	r.Warnings = append(r.Warnings, src.Warnings...)
+	r.TypedErrors = mergeErrors(r.TypedErrors, src.TypedErrors)
+	if r.ExitCode == 0 {
+		r.ExitCode = src.ExitCode
+	}
 }
 
 type BackupBackend interface {
@@ -0,0 +1,158 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// maxErrorPaths caps the paths kept per error kind, so a run with
+// thousands of unreadable files doesn't bloat its history record.
+const maxErrorPaths = 10
+
+// errorRules recognise causes in rsync and ssh stderr, checked in order.
+// ssh's "Permission denied (publickey)" is an auth failure, so it must be
+// matched before rsync's file-level "Permission denied (13)".
+var errorRules = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{config.ErrHostKey, regexp.MustCompile(`Host key verification failed|REMOTE HOST IDENTIFICATION HAS CHANGED`)},
+	{config.ErrAuthFailed, regexp.MustCompile(`Permission denied \((?:publickey|password|keyboard-interactive|gssapi)|Authentication failed|Too many authentication failures`)},
+	{config.ErrConnectionRefused, regexp.MustCompile(`Connection refused`)},
+	{config.ErrNoSpace, regexp.MustCompile(`No space left on device|Disk quota exceeded`)},
+	{config.ErrPermissionDenied, regexp.MustCompile(`Permission denied|Operation not permitted`)},
+	{config.ErrFileVanished, regexp.MustCompile(`file has vanished`)},
+}
+
+// quotedPath finds the file rsync names in a message, e.g.
+// rsync: [sender] send_files failed to open "/srv/a.db": Permission denied (13)
+var quotedPath = regexp.MustCompile(`"([^"]+)"`)
+
+// ClassifyStderr groups stderr lines by recognised cause, in order of first
+// appearance. Lines with no recognised cause are left out.
+func ClassifyStderr(lines []string) []config.RunError {
+	var errs []config.RunError
+	index := make(map[string]int)
+	seen := make(map[[2]string]bool)
+
+	for _, line := range lines {
+		kind := classifyLine(line)
+		if kind == "" {
+			continue
+		}
+
+		i, ok := index[kind]
+		if !ok {
+			i = len(errs)
+			index[kind] = i
+			errs = append(errs, config.RunError{Kind: kind, Message: line})
+		}
+		errs[i].Count++
+		if m := quotedPath.FindStringSubmatch(line); len(m) > 1 && !seen[[2]string{kind, m[1]}] {
+			seen[[2]string{kind, m[1]}] = true
+			errs[i].PathCount++
+			if len(errs[i].Paths) < maxErrorPaths {
+				errs[i].Paths = append(errs[i].Paths, m[1])
+			}
+		}
+	}
+	return errs
+}
+
+func classifyLine(line string) string {
+	for _, rule := range errorRules {
+		if rule.pattern.MatchString(line) {
+			return rule.kind
+		}
+	}
+	return ""
+}
+
+// mergeErrors adds src's typed errors to dst, combining kinds present in both.
+func mergeErrors(dst, src []config.RunError) []config.RunError {
+	for _, e := range src {
+		merged := false
+		for i := range dst {
+			if dst[i].Kind != e.Kind {
+				continue
+			}
+			dst[i].Count += e.Count
+			dst[i].PathCount += e.PathCount
+			room := maxErrorPaths - len(dst[i].Paths)
+			dst[i].Paths = append(dst[i].Paths, e.Paths[:min(room, len(e.Paths))]...)
+			merged = true
+			break
+		}
+		if !merged {
+			e.Paths = slices.Clone(e.Paths)
+			dst = append(dst, e)
+		}
+	}
+	return dst
+}
+
+// ErrorLabel is a short human name for an error kind.
+func ErrorLabel(kind string) string {
+	switch kind {
+	case config.ErrPermissionDenied:
+		return "permission denied"
+	case config.ErrNoSpace:
+		return "no space left"
+	case config.ErrHostKey:
+		return "host key verification failed"
+	case config.ErrAuthFailed:
+		return "authentication failed"
+	case config.ErrConnectionRefused:
+		return "connection refused"
+	case config.ErrFileVanished:
+		return "file vanished"
+	default:
+		return kind
+	}
+}
+
+// Hint suggests how to fix a typed error.
+func Hint(e config.RunError) string {
+	switch e.Kind {
+	case config.ErrPermissionDenied:
+		where := "the affected files"
+		if len(e.Paths) > 0 {
+			where = e.Paths[0]
+			// Records from before PathCount only know the capped list.
+			if total := max(e.PathCount, len(e.Paths)); total > 1 {
+				where += fmt.Sprintf(" and %d more", total-1)
+			}
+		}
+		return fmt.Sprintf("Check ownership and permissions of %s; the backup user must be able to read sources and write the destination. Exclude paths it should not read.", where)
+	case config.ErrNoSpace:
+		return "The destination is out of space or over quota. Free space there (check with df -h on the host) or point the job at a larger volume."
+	case config.ErrHostKey:
+		return "The host key is unknown or has changed. Verify the fingerprint, then run 'ssh-keygen -R <host>' if it changed and connect once with ssh to accept it."
+	case config.ErrAuthFailed:
+		return "SSH login was rejected. Check the destination user and ssh_key, and that the public key is in the remote ~/.ssh/authorized_keys."
+	case config.ErrConnectionRefused:
+		return "Nothing is listening on the SSH port. Check that sshd is running on the host and that the destination port is right."
+	case config.ErrFileVanished:
+		return "Files changed or were deleted during the transfer. Exclude temporary and cache paths, or add exit code 24 to the job's warnings."
+	default:
+		return ""
+	}
+}
+
+// Hints returns one line per typed error: its label, how often it
+// occurred, and the suggested fix.
+func Hints(errs []config.RunError) []string {
+	hints := make([]string, 0, len(errs))
+	for _, e := range errs {
+		label := ErrorLabel(e.Kind)
+		if e.Count > 1 {
+			label += fmt.Sprintf(" (%d)", e.Count)
+		}
+		hints = append(hints, strings.ToUpper(label[:1])+label[1:]+": "+Hint(e))
+	}
+	return hints
+}
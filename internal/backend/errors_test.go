@@ -0,0 +1,74 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+func TestClassifyStderr(t *testing.T) {
+	tests := []struct {
+		line string
+		kind string
+		path string
+	}{
+		{`rsync: [sender] send_files failed to open "/home/u/secret.db": Permission denied (13)`, config.ErrPermissionDenied, "/home/u/secret.db"},
+		{`rsync: [receiver] mkstemp "/backups/docs/.a.txt.Xyz" failed: Permission denied (13)`, config.ErrPermissionDenied, "/backups/docs/.a.txt.Xyz"},
+		{`backup@nas: Permission denied (publickey,password).`, config.ErrAuthFailed, ""},
+		{`Host key verification failed.`, config.ErrHostKey, ""},
+		{`@    WARNING: REMOTE HOST IDENTIFICATION HAS CHANGED!     @`, config.ErrHostKey, ""},
+		{`ssh: connect to host nas port 22: Connection refused`, config.ErrConnectionRefused, ""},
+		{`rsync: [receiver] write failed on "/backups/big.iso": No space left on device (28)`, config.ErrNoSpace, "/backups/big.iso"},
+		{`file has vanished: "/home/u/.cache/chrome/lock"`, config.ErrFileVanished, "/home/u/.cache/chrome/lock"},
+		{`rsync: connection unexpectedly closed (0 bytes received so far) [sender]`, "", ""},
+	}
+
+	for _, tt := range tests {
+		errs := ClassifyStderr([]string{tt.line})
+		if tt.kind == "" {
+			if len(errs) != 0 {
+				t.Errorf("%q classified as %+v", tt.line, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || errs[0].Kind != tt.kind {
+			t.Errorf("%q = %+v, want kind %s", tt.line, errs, tt.kind)
+			continue
+		}
+		if tt.path != "" && (len(errs[0].Paths) != 1 || errs[0].Paths[0] != tt.path) {
+			t.Errorf("%q paths = %v, want %s", tt.line, errs[0].Paths, tt.path)
+		}
+		if Hint(errs[0]) == "" {
+			t.Errorf("no hint for %s", tt.kind)
+		}
+	}
+}
+
+func TestClassifyStderrGroupsByKind(t *testing.T) {
+	var lines []string
+	for i := 0; i < 25; i++ {
+		lines = append(lines, `rsync: [sender] send_files failed to open "/srv/f`+strings.Repeat("x", i)+`": Permission denied (13)`)
+	}
+	lines = append(lines, `file has vanished: "/srv/tmp"`)
+	lines = append(lines, lines[0]) // rsync may name a file twice
+
+	errs := ClassifyStderr(lines)
+	if len(errs) != 2 || errs[0].Count != 26 || errs[0].PathCount != 25 || len(errs[0].Paths) != maxErrorPaths || errs[1].Kind != config.ErrFileVanished {
+		t.Fatalf("errs = %+v", errs)
+	}
+
+	var r Result
+	r.addSource(SourceResult{TypedErrors: errs})
+	r.addSource(SourceResult{TypedErrors: ClassifyStderr(lines[:2])})
+	if r.TypedErrors[0].Count != 28 || len(r.TypedErrors[0].Paths) != maxErrorPaths {
+		t.Errorf("merged = %+v", r.TypedErrors[0])
+	}
+	if !strings.Contains(Hints(r.TypedErrors)[0], "Permission denied (28)") {
+		t.Errorf("hint = %q", Hints(r.TypedErrors)[0])
+	}
+	// "and N more" counts every other path named, not just those kept.
+	if hint := Hint(r.TypedErrors[0]); !strings.Contains(hint, "/srv/f and 26 more") {
+		t.Errorf("hint = %q", hint)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package backend
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachProcessGroup runs the command in its own process group so a Ctrl+C
+// reaches keeper only. Keeper stops rsync itself through the run's context:
+// the daemon after draining, foreground runs straight away.
+func detachProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
@@ -8,8 +8,10 @@ import (
 	"log/slog"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/klederson/keeper/internal/config"
@@ -45,11 +47,22 @@ func (r *RsyncBackend) Validate(job *config.Job) error {
 
 func (r *RsyncBackend) Run(ctx context.Context, job *config.Job, dryRun bool, onProgress func(ProgressEvent)) (*Result, error) {
 	result := &Result{
-		StartedAt: time.Now(),
+		StartedAt:    time.Now(),
+		RsyncVersion: RsyncVersion(),
 	}
 
 	for _, source := range job.Sources {
+		if ctx.Err() != nil {
+			break
+		}
+
+		src := SourceResult{Path: source.Path}
 		args := r.buildArgs(job, &source, dryRun)
+		if supportsProgress2(result.RsyncVersion) {
+			// Overall progress keeps arriving while one large file is
+			// transferred, so the stall timeout doesn't mistake it for a hang.
+			args = append(args, "--info=progress2")
+		}
 		dest := r.buildDest(job)
 		srcPath := config.ExpandPath(source.Path)
 		if !strings.HasSuffix(srcPath, "/") {
@@ -66,11 +79,15 @@ func (r *RsyncBackend) Run(ctx context.Context, job *config.Job, dryRun bool, on
 		)
 
 		cmd := exec.CommandContext(ctx, "rsync", fullArgs...)
+		// Don't hang on pipes still held by ssh after rsync is killed.
+		cmd.WaitDelay = 10 * time.Second
+		detachProcessGroup(cmd)
 
 		// Separate stdout and stderr
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("pipe error: %v", err))
+			src.Errors = append(src.Errors, fmt.Sprintf("pipe error: %v", err))
+			result.addSource(src)
 			continue
 		}
 
@@ -78,23 +95,41 @@ func (r *RsyncBackend) Run(ctx context.Context, job *config.Job, dryRun bool, on
 		cmd.Stderr = &stderrBuf
 
 		if err := cmd.Start(); err != nil {
-			result.Errors = append(result.Errors, fmt.Sprintf("failed to start rsync: %v", err))
+			src.Errors = append(src.Errors, fmt.Sprintf("failed to start rsync: %v", err))
+			result.addSource(src)
 			continue
 		}
 
 		// Read stdout line by line for progress + stats
 		filesCount := 0
+		currentFile := ""
 		scanner := bufio.NewScanner(stdout)
+		scanner.Split(scanLinesOrCR)
 		for scanner.Scan() {
 			line := scanner.Text()
+			if line == "" {
+				continue
+			}
+
+			if isProgressLine(line) {
+				if onProgress != nil {
+					onProgress(ProgressEvent{
+						CurrentFile: currentFile,
+						FilesCount:  filesCount,
+						Phase:       "transferring",
+					})
+				}
+				continue
+			}
 			slog.Debug("rsync", "out", line)
 
 			// Parse stats from the summary block
-			r.parseStatsLine(line, result)
+			r.parseStatsLine(line, &src)
 
 			// Track file transfers for progress
 			if isFileLine(line) {
 				filesCount++
+				currentFile = line
 				if onProgress != nil {
 					onProgress(ProgressEvent{
 						CurrentFile: line,
@@ -110,23 +145,21 @@ func (r *RsyncBackend) Run(ctx context.Context, job *config.Job, dryRun bool, on
 
 		if exitErr != nil {
 			exitCode := cmdExitCode(exitErr)
+			src.ExitCode = exitCode
 			explanation := rsyncExitCodeMessage(exitCode)
 
 			// Collect the most useful error details
 			errParts := []string{fmt.Sprintf("rsync exited with code %d: %s", exitCode, explanation)}
 
 			// Add stderr lines (often contains the real error)
-			if stderrOutput != "" {
-				for _, line := range strings.Split(stderrOutput, "\n") {
-					line = strings.TrimSpace(line)
-					if line != "" && !strings.HasPrefix(line, "rsync error:") {
-						errParts = append(errParts, line)
-					}
-				}
-			}
+			stderr := stderrLines(stderrOutput)
+			errParts = append(errParts, stderr...)
 
-			result.Errors = append(result.Errors, errParts...)
+			src.Errors = append(src.Errors, errParts...)
+			src.TypedErrors = ClassifyStderr(stderr)
+			classifySource(&src, stderr, job.Warnings)
 		}
+		result.addSource(src)
 
 		if onProgress != nil {
 			onProgress(ProgressEvent{Phase: "done"})
@@ -139,6 +172,77 @@ func (r *RsyncBackend) Run(ctx context.Context, job *config.Job, dryRun bool, on
 	return result, nil
 }
 
+var (
+	rsyncVersionOnce sync.Once
+	rsyncVersion     string
+	// "rsync  version 3.2.7  protocol version 31"
+	rsyncVersionPattern = regexp.MustCompile(`version v?(\S+)`)
+)
+
+// RsyncVersion returns the installed rsync's version, or "" if it can't be
+// determined. It is looked up once per process.
+func RsyncVersion() string {
+	rsyncVersionOnce.Do(func() {
+		out, err := exec.Command("rsync", "--version").Output()
+		if err != nil {
+			return
+		}
+		first, _, _ := strings.Cut(string(out), "\n")
+		if m := rsyncVersionPattern.FindStringSubmatch(first); len(m) > 1 {
+			rsyncVersion = m[1]
+		}
+	})
+	return rsyncVersion
+}
+
+// stderrLines returns rsync's stderr messages, without blank lines and its
+// closing "rsync error:" summary.
+func stderrLines(stderr string) []string {
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "rsync error:") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// classifySource turns a failed source's errors into warnings when the
+// job's rules expect them: its exit code is listed, or every stderr line
+// matches a pattern.
+func classifySource(src *SourceResult, stderr []string, rules *config.Warnings) {
+	if rules == nil || len(src.Errors) == 0 {
+		return
+	}
+
+	expected := slices.Contains(rules.ExitCodes, src.ExitCode)
+	if !expected && len(stderr) > 0 && len(rules.Patterns) > 0 {
+		expected = true
+		for _, line := range stderr {
+			if !matchesAny(line, rules.Patterns) {
+				expected = false
+				break
+			}
+		}
+	}
+
+	if expected {
+		src.Warnings = append(src.Warnings, src.Errors...)
+		src.Errors = nil
+	}
+}
+
+func matchesAny(line string, patterns []string) bool {
+	for _, p := range patterns {
+		// Patterns are checked by config.Validate.
+		if re, err := regexp.Compile(p); err == nil && re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *RsyncBackend) buildArgs(job *config.Job, source *config.Source, dryRun bool) []string {
 	args := []string{"-av", "--stats", "--human-readable"}
 
@@ -205,7 +309,7 @@ var (
 	xferSizePattern = regexp.MustCompile(`Total transferred file size: ([\d,\.]+(?:\.\d+)?[KMG]?) bytes`)
 )
 
-func (r *RsyncBackend) parseStatsLine(line string, result *Result) {
+func (r *RsyncBackend) parseStatsLine(line string, result *SourceResult) {
 	if m := filesPattern.FindStringSubmatch(line); len(m) > 1 {
 		result.FilesTotal = parseIntComma(m[1])
 	}
@@ -222,6 +326,40 @@ func (r *RsyncBackend) parseStatsLine(line string, result *Result) {
 
 // isFileLine returns true if the line looks like a file being transferred
 // (not a stats line, not blank, not a header)
+// supportsProgress2 reports whether an rsync version understands
+// --info=progress2, added in 3.1.0.
+func supportsProgress2(version string) bool {
+	m := versionNumberPattern.FindStringSubmatch(version)
+	if m == nil {
+		return false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return major > 3 || (major == 3 && minor >= 1)
+}
+
+var versionNumberPattern = regexp.MustCompile(`^(\d+)\.(\d+)`)
+
+// scanLinesOrCR splits rsync output on newlines and on the carriage returns
+// --info=progress2 uses to redraw its progress line.
+func scanLinesOrCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// "      1.23M  45%    1.20MB/s    0:00:10 (xfr#3, to-chk=10/20)"
+var progressPattern = regexp.MustCompile(`^\s+[\d.,]+[KMGTP]?\s+\d+%\s`)
+
+// isProgressLine reports whether line is an --info=progress2 update.
+func isProgressLine(line string) bool {
+	return progressPattern.MatchString(line)
+}
+
 func isFileLine(line string) bool {
 	if line == "" {
 		return false
@@ -1,6 +1,8 @@
 package backend
 
 import (
+	"bufio"
+	"strings"
 	"testing"
 
 	"github.com/klederson/keeper/internal/config"
@@ -175,7 +177,7 @@ func TestParseStatsLine(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := &Result{}
+		result := &SourceResult{}
 		r.parseStatsLine(tt.line, result)
 
 		if tt.wantFiles > 0 && result.FilesTotal != tt.wantFiles {
@@ -253,6 +255,31 @@ func TestIsFileLine(t *testing.T) {
 	}
 }
 
+func TestProgressLines(t *testing.T) {
+	out := "sending incremental file list\nbig.iso\n     32.77K   0%    0.00kB/s    0:00:00  \r    512.00M  50%  100.00MB/s    0:00:05  \r      1.00G 100%  100.00MB/s    0:00:10 (xfr#1, to-chk=0/2)\n\nsent 1.00G bytes  received 35 bytes\n"
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Split(scanLinesOrCR)
+
+	var files, progress int
+	for scanner.Scan() {
+		switch line := scanner.Text(); {
+		case isProgressLine(line):
+			progress++
+		case isFileLine(line):
+			files++
+		}
+	}
+	if files != 1 || progress != 3 {
+		t.Errorf("files = %d, progress updates = %d; want 1, 3", files, progress)
+	}
+
+	for version, want := range map[string]bool{"3.2.7": true, "3.1.0": true, "3.0.9": false, "2.6.9": false, "": false} {
+		if got := supportsProgress2(version); got != want {
+			t.Errorf("supportsProgress2(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
+
 func TestParseSizeBytes(t *testing.T) {
 	tests := []struct {
 		input string
@@ -272,3 +299,55 @@ func TestParseSizeBytes(t *testing.T) {
 		}
 	}
 }
+
+func TestAddSourceSumsTotals(t *testing.T) {
+	var r Result
+	r.addSource(SourceResult{Path: "/a", FilesTotal: 10, FilesTransferred: 2, BytesTotal: 100, BytesTransferred: 20})
+	r.addSource(SourceResult{Path: "/b", FilesTotal: 5, BytesTotal: 50, Errors: []string{"vanished"}, ExitCode: 24})
+	r.addSource(SourceResult{Path: "/c", Errors: []string{"denied"}, ExitCode: 23})
+
+	if r.FilesTotal != 15 || r.FilesTransferred != 2 || r.BytesTotal != 150 || r.BytesTransferred != 20 {
+		t.Errorf("totals = %+v", r)
+	}
+	if len(r.Sources) != 3 || len(r.Errors) != 2 || r.ExitCode != 24 {
+		t.Errorf("sources %d, errors %v, exit code %d", len(r.Sources), r.Errors, r.ExitCode)
+	}
+}
+
+func TestClassifySource(t *testing.T) {
+	vanished := []string{"file has vanished: \"/home/u/.cache/x\""}
+	tests := []struct {
+		name     string
+		exitCode int
+		stderr   []string
+		rules    *config.Warnings
+		warning  bool
+	}{
+		{"no rules", 24, vanished, nil, false},
+		{"listed exit code", 24, vanished, &config.Warnings{ExitCodes: []int{24}}, true},
+		{"other exit code", 23, vanished, &config.Warnings{ExitCodes: []int{24}}, false},
+		{"all lines match", 23, vanished, &config.Warnings{Patterns: []string{`^file has vanished`}}, true},
+		{"one line unmatched", 23, append(vanished, "Permission denied (13)"), &config.Warnings{Patterns: []string{`^file has vanished`}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := SourceResult{ExitCode: tt.exitCode, Errors: append([]string{"rsync exited"}, tt.stderr...)}
+			classifySource(&src, tt.stderr, tt.rules)
+			if got := len(src.Errors) == 0 && len(src.Warnings) > 0; got != tt.warning {
+				t.Errorf("warning = %v, want %v (errors %v)", got, tt.warning, src.Errors)
+			}
+
+			var r Result
+			r.addSource(src)
+			r.Success = len(r.Errors) == 0
+			want := config.OutcomeFailure
+			if tt.warning {
+				want = config.OutcomeWarning
+			}
+			if r.Outcome() != want {
+				t.Errorf("Outcome() = %q, want %q", r.Outcome(), want)
+			}
+		})
+	}
+}
@@ -4,21 +4,39 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/klederson/keeper/internal/backend"
 	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/ping"
+	"github.com/klederson/keeper/internal/reporter"
 	"github.com/klederson/keeper/internal/ui"
 )
 
+// RunJob runs one job on its backend, enforcing the job's timeout and stall
+// timeout. A run stopped by an AbortError cause is marked failed with the
+// abort reason and message.
 func RunJob(ctx context.Context, job *config.Job, dryRun bool, onProgress func(backend.ProgressEvent)) (*backend.Result, error) {
+	runID := ping.NewRunID()
+
+	// Dry runs are not backups, so monitors don't hear about them.
+	var pinger *ping.Pinger
+	if !dryRun {
+		pinger = ping.New(job, runID)
+	}
+
 	b, err := backend.New(job.Destination.Type)
 	if err != nil {
-		return nil, fmt.Errorf("creating backend: %w", err)
+		err = fmt.Errorf("creating backend: %w", err)
+		pinger.Finish(false, err.Error())
+		return nil, err
 	}
 
 	if err := b.Validate(job); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+		err = fmt.Errorf("validation failed: %w", err)
+		pinger.Finish(false, err.Error())
+		return nil, err
 	}
 
 	mode := "backup"
@@ -30,14 +48,49 @@ func RunJob(ctx context.Context, job *config.Job, dryRun bool, onProgress func(b
 		"job", job.Name,
 		"mode", mode,
 		"backend", b.Name(),
+		"run_id", runID,
 	)
 
-	result, err := b.Run(ctx, job, dryRun, onProgress)
+	pinger.Start()
+	runCtx, onProgress, stop := withLimits(ctx, job, onProgress)
+	result, err := b.Run(runCtx, job, dryRun, onProgress)
+	abort := stop()
 	if err != nil {
-		return result, fmt.Errorf("backup failed: %w", err)
+		err = fmt.Errorf("backup failed: %w", err)
+	}
+
+	// Pings go out only after the limits are stopped, so a slow monitor
+	// can't time out or stall a run that already finished.
+	success := err == nil && result != nil && result.Success && abort == nil
+	summary := failureSummary(abort, result, err)
+	if result != nil {
+		result.RunID = runID
+		if abort != nil {
+			slog.Warn("job aborted", "job", job.Name, "reason", abort.Reason)
+			result.AbortReason = abort.Reason
+			result.Success = false
+			result.Errors = append([]string{abort.Message}, result.Errors...)
+		}
 	}
+	pinger.Finish(success, summary)
 
-	return result, nil
+	return result, err
+}
+
+// failureSummary describes why a run failed for the fail ping: the abort
+// reason if the run was cut short, then the run error and rsync's errors.
+func failureSummary(abort *AbortError, result *backend.Result, err error) string {
+	var lines []string
+	if abort != nil {
+		lines = append(lines, abort.Message)
+	}
+	if err != nil {
+		lines = append(lines, err.Error())
+	}
+	if result != nil {
+		lines = append(lines, result.Errors...)
+	}
+	return strings.Join(lines, "\n")
 }
 
 func PrintResult(jobName string, result *backend.Result, dryRun bool) {
@@ -51,7 +104,10 @@ func PrintResult(jobName string, result *backend.Result, dryRun bool) {
 	}
 
 	status := ui.Success("completed successfully")
-	if !result.Success {
+	switch result.Outcome() {
+	case config.OutcomeWarning:
+		status = ui.Warn("completed with warnings")
+	case config.OutcomeFailure:
 		status = ui.Error("completed with errors")
 	}
 	fmt.Println("  " + status)
@@ -61,27 +117,39 @@ func PrintResult(jobName string, result *backend.Result, dryRun bool) {
 		{"Duration", duration.String()},
 		{"Files total", fmt.Sprintf("%d", result.FilesTotal)},
 		{"Files transferred", fmt.Sprintf("%d", result.FilesTransferred)},
-		{"Total size", formatBytes(result.BytesTotal)},
-		{"Transferred", formatBytes(result.BytesTransferred)},
+		{"Total size", reporter.FormatBytes(result.BytesTotal)},
+		{"Transferred", reporter.FormatBytes(result.BytesTransferred)},
 	}))
 
+	if len(result.Sources) > 1 {
+		fmt.Println(ui.Section("Sources"))
+		for _, src := range result.Sources {
+			icon := ui.StatusIcon(len(src.Errors) == 0)
+			if len(src.Errors) == 0 && len(src.Warnings) > 0 {
+				icon = ui.OutcomeIcon(config.OutcomeWarning)
+			}
+			fmt.Printf("  %s %s  %s\n", icon, src.Path, ui.MutedStyle.Render(fmt.Sprintf("%d files, %s transferred", src.FilesTransferred, reporter.FormatBytes(src.BytesTransferred))))
+		}
+	}
+
+	if len(result.Warnings) > 0 {
+		fmt.Println(ui.Section("Warnings"))
+		for _, w := range result.Warnings {
+			fmt.Println("  " + ui.Warn(w))
+		}
+	}
+
 	if len(result.Errors) > 0 {
 		fmt.Println(ui.Section("Errors"))
 		for _, e := range result.Errors {
 			fmt.Println("  " + ui.Error(e))
 		}
 	}
-}
 
-func formatBytes(b int64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
-	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+	if len(result.TypedErrors) > 0 {
+		fmt.Println(ui.Section("Suggested Fixes"))
+		for _, hint := range backend.Hints(result.TypedErrors) {
+			fmt.Println("  " + ui.Info(hint))
+		}
 	}
-	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
 }
@@ -2,22 +2,76 @@ package backup
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/klederson/keeper/internal/backend"
 	"github.com/klederson/keeper/internal/config"
 )
 
+// AbortError is used as a context cancellation cause to stop a running job
+// for a known reason. The reason is recorded in the run's result.
+type AbortError struct {
+	Reason  string
+	Message string
+}
+
+func (e *AbortError) Error() string {
+	return e.Message
+}
+
+func NewAbortError(reason, message string) *AbortError {
+	return &AbortError{Reason: reason, Message: message}
+}
+
+type EventType string
+
+const (
+	EventStarted  EventType = "started"
+	EventFinished EventType = "finished"
+)
+
+// Event reports a job starting or finishing. Result and Err are only set on
+// EventFinished; Result is nil if the job could not be started at all.
+type Event struct {
+	Type   EventType
+	Job    string
+	Time   time.Time
+	DryRun bool
+	Result *backend.Result
+	Err    error
+}
+
 type Orchestrator struct {
-	mu      sync.Mutex
-	running map[string]context.CancelFunc
+	mu          sync.Mutex
+	running     map[string]context.CancelCauseFunc
+	subscribers []func(Event)
 }
 
 func NewOrchestrator() *Orchestrator {
 	return &Orchestrator{
-		running: make(map[string]context.CancelFunc),
+		running: make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// Subscribe registers fn to receive job lifecycle events. It is called
+// synchronously from the goroutine running the job.
+func (o *Orchestrator) Subscribe(fn func(Event)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.subscribers = append(o.subscribers, fn)
+}
+
+func (o *Orchestrator) emit(evt Event) {
+	o.mu.Lock()
+	subs := append([]func(Event){}, o.subscribers...)
+	o.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(evt)
 	}
 }
 
@@ -28,6 +82,9 @@ func (o *Orchestrator) IsRunning(jobName string) bool {
 	return ok
 }
 
+// Run executes a job, enforcing its timeout and stall timeout. When the run
+// is stopped by an AbortError cause, the result is marked as failed with the
+// abort reason and message; see RunJob.
 func (o *Orchestrator) Run(ctx context.Context, job *config.Job, dryRun bool, onProgress func(backend.ProgressEvent)) (*backend.Result, error) {
 	o.mu.Lock()
 	if _, running := o.running[job.Name]; running {
@@ -35,24 +92,80 @@ func (o *Orchestrator) Run(ctx context.Context, job *config.Job, dryRun bool, on
 		return nil, fmt.Errorf("job %q is already running", job.Name)
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
+	ctx, cancel := context.WithCancelCause(ctx)
 	o.running[job.Name] = cancel
 	o.mu.Unlock()
 
 	defer func() {
+		cancel(nil)
 		o.mu.Lock()
 		delete(o.running, job.Name)
 		o.mu.Unlock()
 	}()
 
-	return RunJob(ctx, job, dryRun, onProgress)
+	o.emit(Event{Type: EventStarted, Job: job.Name, Time: time.Now(), DryRun: dryRun})
+
+	result, err := RunJob(ctx, job, dryRun, onProgress)
+
+	o.emit(Event{Type: EventFinished, Job: job.Name, Time: time.Now(), DryRun: dryRun, Result: result, Err: err})
+	return result, err
+}
+
+// withLimits derives the context a backend runs under, enforcing the job's
+// timeout and stall timeout. Progress must go through the returned callback
+// for stalls to be noticed. stop ends both limits and returns the
+// AbortError that cut the run short, if any; whatever cancels ctx after
+// that no longer counts against the run.
+func withLimits(ctx context.Context, job *config.Job, onProgress func(backend.ProgressEvent)) (context.Context, func(backend.ProgressEvent), func() *AbortError) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	cancelTimeout := context.CancelFunc(func() {})
+	if job.Timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, job.Timeout,
+			NewAbortError(config.AbortTimeout, fmt.Sprintf("timed out after %s", job.Timeout)))
+	}
+
+	var stall *time.Timer
+	if job.StallTimeout > 0 {
+		stall = time.AfterFunc(job.StallTimeout, func() {
+			slog.Warn("job stalled, cancelling", "job", job.Name, "stall_timeout", job.StallTimeout)
+			cancel(NewAbortError(config.AbortStalled, fmt.Sprintf("no progress for %s", job.StallTimeout)))
+		})
+
+		// Every progress event resets the timer. rsync before 3.1 reports
+		// nothing while one file is copied, so there a single file that takes
+		// longer than StallTimeout still counts as a stall.
+		inner := onProgress
+		onProgress = func(evt backend.ProgressEvent) {
+			stall.Reset(job.StallTimeout)
+			if inner != nil {
+				inner(evt)
+			}
+		}
+	}
+
+	stop := func() *AbortError {
+		if stall != nil {
+			stall.Stop()
+		}
+		var abort *AbortError
+		errors.As(context.Cause(ctx), &abort)
+		cancelTimeout()
+		cancel(nil)
+		return abort
+	}
+	return ctx, onProgress, stop
 }
 
+// RunAll runs jobs one after another. Jobs not yet started when ctx is
+// cancelled are skipped and left out of the results.
 func (o *Orchestrator) RunAll(ctx context.Context, jobs []config.Job, dryRun bool, onProgress func(string, backend.ProgressEvent)) map[string]*backend.Result {
 	results := make(map[string]*backend.Result)
 	var mu sync.Mutex
 
 	for i := range jobs {
+		if ctx.Err() != nil {
+			break
+		}
 		job := &jobs[i]
 
 		slog.Info("running job", "job", job.Name)
@@ -82,10 +195,31 @@ func (o *Orchestrator) RunAll(ctx context.Context, jobs []config.Job, dryRun boo
 	return results
 }
 
-func (o *Orchestrator) Cancel(jobName string) {
+// Running lists the jobs currently being executed.
+func (o *Orchestrator) Running() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	names := make([]string, 0, len(o.running))
+	for name := range o.running {
+		names = append(names, name)
+	}
+	return names
+}
+
+// CancelAll stops every running job with the given cause.
+func (o *Orchestrator) CancelAll(cause error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, cancel := range o.running {
+		cancel(cause)
+	}
+}
+
+// Cancel stops a running job. A nil cause cancels it without an abort reason.
+func (o *Orchestrator) Cancel(jobName string, cause error) {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 	if cancel, ok := o.running[jobName]; ok {
-		cancel()
+		cancel(cause)
 	}
 }
@@ -0,0 +1,31 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/backend"
+	"github.com/klederson/keeper/internal/config"
+)
+
+func TestWithLimitsStall(t *testing.T) {
+	job := &config.Job{Name: "web", StallTimeout: 10 * time.Millisecond}
+	ctx, _, stop := withLimits(context.Background(), job, nil)
+	<-ctx.Done()
+	if abort := stop(); abort == nil || abort.Reason != config.AbortStalled {
+		t.Errorf("stop() = %+v, want a stall", abort)
+	}
+}
+
+func TestWithLimitsProgressResetsStall(t *testing.T) {
+	job := &config.Job{Name: "web", StallTimeout: 20 * time.Millisecond}
+	ctx, onProgress, stop := withLimits(context.Background(), job, nil)
+	for range 5 {
+		time.Sleep(10 * time.Millisecond)
+		onProgress(backend.ProgressEvent{})
+	}
+	if abort := stop(); abort != nil || ctx.Err() == nil {
+		t.Errorf("stop() = %+v, ctx.Err() = %v; want no abort and a cancelled context", abort, ctx.Err())
+	}
+}
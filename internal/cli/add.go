@@ -9,24 +9,54 @@ import (
 	"github.com/klederson/keeper/internal/ui"
 )
 
+var addFromFile string
+
 var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a new backup job",
-	Long:  "Interactive form to configure and add a new backup job.",
+	Long: `Add a new backup job. Without flags an interactive form asks for the
+details; with them the job is created non-interactively, e.g.
+
+  keeper add --name docs --source ~/Documents --source ~/Pictures \
+    --exclude '*.tmp' --dest backup@nas.local:/backups/docs --schedule @daily
+
+--from-file reads the job as YAML (as written under jobs: in the config),
+or from stdin with "-"; flags given alongside override its fields.
+Destination fields left unset are taken from the config's defaults.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.Load()
 		if err != nil {
 			return err
 		}
 
-		job, err := ui.RunAddJobForm(nil)
-		if err != nil {
-			return err
+		var job *config.Job
+		switch {
+		case addFromFile != "":
+			if job, err = readJobFile(addFromFile); err != nil {
+				return err
+			}
+			if err := applyJobFlags(cmd, job); err != nil {
+				return err
+			}
+		case jobFlagsGiven(cmd):
+			job = &config.Job{Bandwidth: "0", Compress: true}
+			if err := applyJobFlags(cmd, job); err != nil {
+				return err
+			}
+		default:
+			if job, err = ui.RunAddJobForm(cfg.Defaults); err != nil {
+				return err
+			}
 		}
 
 		if err := cfg.AddJob(*job); err != nil {
 			return err
 		}
+		cfg.ApplyDefaults()
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
 
 		if err := config.Save(cfg); err != nil {
 			return err
@@ -34,11 +64,17 @@ var addCmd = &cobra.Command{
 
 		fmt.Println()
 		fmt.Println(ui.Success(fmt.Sprintf("Job %q added successfully", job.Name)))
-		fmt.Println(ui.Label("  Source", job.Sources[0].Path))
-		fmt.Println(ui.Label("  Destination", fmt.Sprintf("%s@%s:%s", job.Destination.User, job.Destination.Host, job.Destination.Path)))
-		fmt.Println(ui.Label("  Schedule", job.Schedule))
+		fmt.Println(ui.Label("  Source", sourcesLabel(job)))
+		dest := cfg.Jobs[len(cfg.Jobs)-1].Destination
+		fmt.Println(ui.Label("  Destination", fmt.Sprintf("%s@%s:%s", dest.User, dest.Host, dest.Path)))
+		fmt.Println(ui.Label("  Schedule", scheduleLabel(job)))
 		fmt.Println()
 		fmt.Println(ui.Info("Run 'keeper test " + job.Name + "' to verify the connection"))
 		return nil
 	},
 }
+
+func init() {
+	addJobFlags(addCmd)
+	addCmd.Flags().StringVar(&addFromFile, "from-file", "", "Read the job from a YAML file (- for stdin)")
+}
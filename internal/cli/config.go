@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+var configResolved bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the configuration",
+	Long:  "Print the configuration as saved. With --resolved, jobs show the effective values, including destination fields inherited from defaults.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		if !configResolved {
+			cfg = cfg.Unresolved()
+		}
+		data, err := yaml.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	},
+}
+
+func init() {
+	configShowCmd.Flags().BoolVar(&configResolved, "resolved", false, "Show effective values with defaults applied")
+	configCmd.AddCommand(configShowCmd)
+}
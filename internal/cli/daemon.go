@@ -3,15 +3,21 @@ package cli
 import (
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/metrics"
+	"github.com/klederson/keeper/internal/notify"
+	"github.com/klederson/keeper/internal/reporter"
 	"github.com/klederson/keeper/internal/scheduler"
 	"github.com/klederson/keeper/internal/ui"
 )
@@ -48,10 +54,26 @@ var daemonStartCmd = &cobra.Command{
 		fmt.Println(ui.Label("  Jobs", fmt.Sprintf("%d", len(cfg.Jobs))))
 
 		sched := scheduler.New()
+		sched.SetNotifier(notify.NewDispatcher(cfg))
 		if err := sched.LoadFromConfig(cfg); err != nil {
 			return fmt.Errorf("loading scheduler: %w", err)
 		}
 
+		var collector *metrics.Collector
+		if cfg.Metrics.Listen != "" {
+			collector = metrics.New()
+			collector.Seed(reporter.NewStore(), cfg.Jobs)
+			collector.QueueDepth = sched.QueueDepth
+			sched.Subscribe(collector.HandleEvent)
+
+			srv, err := startMetricsServer(cfg.Metrics.Listen, collector)
+			if err != nil {
+				return err
+			}
+			defer srv.Close()
+			fmt.Println(ui.Label("  Metrics", "http://"+cfg.Metrics.Listen+"/metrics"))
+		}
+
 		sched.Start()
 		fmt.Println(ui.Success("Scheduler running"))
 
@@ -59,35 +81,62 @@ var daemonStartCmd = &cobra.Command{
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
+		configChanged := make(chan struct{}, 1)
+		stopWatch := make(chan struct{})
+		defer close(stopWatch)
+		go watchConfigFile(config.ConfigPath(), configPollInterval, configChanged, stopWatch)
+
+		reload := func(reason string) {
+			slog.Info("reloading config", "reason", reason)
+			fmt.Println(ui.Info("Reloading configuration..."))
+
+			newCfg, err := config.Load()
+			if err == nil {
+				err = newCfg.Validate()
+			}
+			if err != nil {
+				slog.Error("failed to reload config", "error", err)
+				fmt.Println(ui.Error("Failed to reload: " + err.Error()))
+				return
+			}
+
+			added, changed, removed := sched.Reload(newCfg)
+			sched.SetNotifier(notify.NewDispatcher(newCfg))
+			if collector != nil {
+				collector.Seed(reporter.NewStore(), newCfg.Jobs)
+			}
+			if newCfg.Metrics.Listen != cfg.Metrics.Listen {
+				fmt.Println(ui.Warn("Metrics address changed — restart the daemon to apply it"))
+			}
+			cfg = newCfg
+			fmt.Println(ui.Success(fmt.Sprintf("Configuration reloaded (%d added, %d changed, %d removed)", added, changed, removed)))
+		}
+
 		for {
-			sig := <-sigChan
-			switch sig {
-			case syscall.SIGHUP:
-				slog.Info("received SIGHUP, reloading config")
-				fmt.Println(ui.Info("Reloading configuration..."))
-
-				newCfg, err := config.Load()
-				if err != nil {
-					slog.Error("failed to reload config", "error", err)
-					fmt.Println(ui.Error("Failed to reload: " + err.Error()))
-					continue
-				}
+			select {
+			case <-configChanged:
+				reload("config file changed")
+
+			case sig := <-sigChan:
+				switch sig {
+				case syscall.SIGHUP:
+					reload("SIGHUP")
 
-				sched.Stop()
-				sched = scheduler.New()
-				if err := sched.LoadFromConfig(newCfg); err != nil {
-					slog.Error("failed to reload scheduler", "error", err)
+				case syscall.SIGINT, syscall.SIGTERM:
+					slog.Info("received shutdown signal", "signal", sig)
+					fmt.Println()
+
+					grace := cfg.ShutdownGrace()
+					if running := sched.Running(); len(running) > 0 {
+						fmt.Println(ui.Info(fmt.Sprintf("Waiting up to %s for %d running job(s)...", grace, len(running))))
+					} else {
+						fmt.Println(ui.Info("Shutting down..."))
+					}
+
+					sched.Shutdown(grace)
+					fmt.Println(ui.Success("Daemon stopped"))
+					return nil
 				}
-				sched.Start()
-				fmt.Println(ui.Success("Configuration reloaded"))
-
-			case syscall.SIGINT, syscall.SIGTERM:
-				slog.Info("received shutdown signal", "signal", sig)
-				fmt.Println()
-				fmt.Println(ui.Info("Shutting down..."))
-				sched.Stop()
-				fmt.Println(ui.Success("Daemon stopped"))
-				return nil
 			}
 		}
 	},
@@ -151,6 +200,73 @@ func init() {
 	daemonCmd.AddCommand(daemonStatusCmd)
 }
 
+// startMetricsServer serves the collector on addr in the background. The
+// listener is opened synchronously so address errors fail startup.
+func startMetricsServer(addr string, collector *metrics.Collector) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics listener: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", collector)
+
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics server", "error", err)
+		}
+	}()
+	slog.Info("serving metrics", "addr", addr)
+	return srv, nil
+}
+
+const configPollInterval = 5 * time.Second
+
+// watchConfigFile polls the config file and signals changed once its
+// modification time or size changes and then stays the same for one more
+// poll, so half-written saves are not picked up.
+func watchConfigFile(path string, interval time.Duration, changed chan<- struct{}, stop <-chan struct{}) {
+	stat := func() (time.Time, int64) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+
+	lastMod, lastSize := stat()
+	pending := false
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		mod, size := stat()
+		if size < 0 {
+			continue
+		}
+		if !mod.Equal(lastMod) || size != lastSize {
+			lastMod, lastSize = mod, size
+			pending = true
+			continue
+		}
+		if pending {
+			pending = false
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
 func pidFilePath() string {
 	return filepath.Join(config.DataDir(), "keeper.pid")
 }
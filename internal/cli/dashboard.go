@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/pause"
 	"github.com/klederson/keeper/internal/reporter"
 	"github.com/klederson/keeper/internal/ui"
 )
@@ -20,7 +21,7 @@ var dashboardCmd = &cobra.Command{
 		}
 
 		store := reporter.NewStore()
-		model := ui.NewDashboard(cfg, store)
+		model := ui.NewDashboard(cfg, store, pause.NewStore())
 
 		p := tea.NewProgram(model)
 		_, err = p.Run()
@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -13,97 +14,64 @@ import (
 	"github.com/klederson/keeper/internal/ui"
 )
 
+// Doctor check statuses.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// doctorCheck is one line of the doctor report. Detail is a follow-up line:
+// the rsync version, or how to fix the problem.
+type doctorCheck struct {
+	Section string `json:"section"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+}
+
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Check system dependencies and connectivity",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println(ui.Banner())
-		fmt.Println(ui.Section("System Check"))
+		checks := runDoctorChecks()
 
 		allOk := true
-
-		// Check rsync
-		if path, err := exec.LookPath("rsync"); err != nil {
-			fmt.Println(ui.Error("rsync not found in PATH"))
-			fmt.Println(ui.Info("  Install: sudo apt install rsync (Debian/Ubuntu)"))
-			allOk = false
-		} else {
-			out, _ := exec.Command("rsync", "--version").Output()
-			version := firstLine(string(out))
-			fmt.Println(ui.Success(fmt.Sprintf("rsync found: %s", path)))
-			fmt.Println(ui.MutedStyle.Render("  " + version))
+		for _, c := range checks {
+			if c.Status == checkFail {
+				allOk = false
+			}
 		}
 
-		// Check ssh
-		if path, err := exec.LookPath("ssh"); err != nil {
-			fmt.Println(ui.Error("ssh not found in PATH"))
-			allOk = false
-		} else {
-			fmt.Println(ui.Success(fmt.Sprintf("ssh found: %s", path)))
+		if structuredOutput() {
+			return printOutput(cmd, doctorOutput{OK: allOk, Checks: checks})
 		}
 
-		// Check config
-		fmt.Println()
-		fmt.Println(ui.Section("Configuration"))
-
-		cfg, err := config.Load()
-		if err != nil {
-			fmt.Println(ui.Error("Config: " + err.Error()))
-			allOk = false
-		} else {
-			fmt.Println(ui.Success(fmt.Sprintf("Config loaded: %d job(s)", len(cfg.Jobs))))
-
-			if err := cfg.Validate(); err != nil {
-				fmt.Println(ui.Error("Validation: " + err.Error()))
-				allOk = false
-			} else {
-				fmt.Println(ui.Success("Config validation passed"))
+		fmt.Println(ui.Banner())
+		section := ""
+		for _, c := range checks {
+			if c.Section != section {
+				if section != "" {
+					fmt.Println()
+				}
+				section = c.Section
+				fmt.Println(ui.Section(section))
 			}
-
-			// Check connectivity for each job destination
-			if len(cfg.Jobs) > 0 {
-				fmt.Println()
-				fmt.Println(ui.Section("Connectivity"))
-
-				seen := make(map[string]bool)
-				for _, job := range cfg.Jobs {
-					key := fmt.Sprintf("%s:%d", job.Destination.Host, job.Destination.Port)
-					if seen[key] {
-						continue
-					}
-					seen[key] = true
-
-					port := job.Destination.Port
-					if port == 0 {
-						port = 22
-					}
-					addr := fmt.Sprintf("%s:%d", job.Destination.Host, port)
-
-					conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
-					if err != nil {
-						fmt.Println(ui.Error(fmt.Sprintf("%s — connection failed: %v", addr, err)))
-						allOk = false
-					} else {
-						conn.Close()
-						fmt.Println(ui.Success(fmt.Sprintf("%s — reachable", addr)))
-					}
+			switch c.Status {
+			case checkOK:
+				fmt.Println(ui.Success(c.Message))
+				if c.Detail != "" {
+					fmt.Println(ui.MutedStyle.Render("  " + c.Detail))
 				}
+			case checkWarn:
+				fmt.Println(ui.Warn(c.Message))
+			default:
+				fmt.Println(ui.Error(c.Message))
+			}
+			if c.Status != checkOK && c.Detail != "" {
+				fmt.Println(ui.Info("  " + c.Detail))
 			}
-		}
-
-		// Check data dir
-		fmt.Println()
-		fmt.Println(ui.Section("Storage"))
-
-		dataDir := config.DataDir()
-		if info, err := os.Stat(dataDir); err != nil {
-			fmt.Println(ui.Warn(fmt.Sprintf("Data directory missing: %s", dataDir)))
-			fmt.Println(ui.Info("  Will be created on first run"))
-		} else if !info.IsDir() {
-			fmt.Println(ui.Error(fmt.Sprintf("%s exists but is not a directory", dataDir)))
-			allOk = false
-		} else {
-			fmt.Println(ui.Success(fmt.Sprintf("Data directory: %s", dataDir)))
 		}
 
 		// Summary
@@ -118,6 +86,78 @@ var doctorCmd = &cobra.Command{
 	},
 }
 
+func runDoctorChecks() []doctorCheck {
+	var checks []doctorCheck
+	add := func(section, name, status, msg, detail string) {
+		checks = append(checks, doctorCheck{Section: section, Name: name, Status: status, Message: msg, Detail: detail})
+	}
+
+	// Check rsync
+	if path, err := exec.LookPath("rsync"); err != nil {
+		add("System Check", "rsync", checkFail, "rsync not found in PATH", "Install: sudo apt install rsync (Debian/Ubuntu)")
+	} else {
+		out, _ := exec.Command("rsync", "--version").Output()
+		add("System Check", "rsync", checkOK, fmt.Sprintf("rsync found: %s", path), firstLine(string(out)))
+	}
+
+	// Check ssh
+	if path, err := exec.LookPath("ssh"); err != nil {
+		add("System Check", "ssh", checkFail, "ssh not found in PATH", "")
+	} else {
+		add("System Check", "ssh", checkOK, fmt.Sprintf("ssh found: %s", path), "")
+	}
+
+	// Check config
+	cfg, err := config.Load()
+	if err != nil {
+		add("Configuration", "config", checkFail, "Config: "+err.Error(), "")
+	} else {
+		add("Configuration", "config", checkOK, fmt.Sprintf("Config loaded: %d job(s)", len(cfg.Jobs)), "")
+
+		if err := cfg.Validate(); err != nil {
+			add("Configuration", "validation", checkFail, "Validation: "+err.Error(), "")
+		} else {
+			add("Configuration", "validation", checkOK, "Config validation passed", "")
+		}
+
+		// Check connectivity for each job destination
+		seen := make(map[string]bool)
+		for _, job := range cfg.Jobs {
+			key := fmt.Sprintf("%s:%d", job.Destination.Host, job.Destination.Port)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			port := job.Destination.Port
+			if port == 0 {
+				port = 22
+			}
+			addr := net.JoinHostPort(job.Destination.Host, strconv.Itoa(port))
+
+			conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+			if err != nil {
+				add("Connectivity", addr, checkFail, fmt.Sprintf("%s — connection failed: %v", addr, err), "")
+			} else {
+				conn.Close()
+				add("Connectivity", addr, checkOK, fmt.Sprintf("%s — reachable", addr), "")
+			}
+		}
+	}
+
+	// Check data dir
+	dataDir := config.DataDir()
+	if info, err := os.Stat(dataDir); err != nil {
+		add("Storage", "data_dir", checkWarn, fmt.Sprintf("Data directory missing: %s", dataDir), "Will be created on first run")
+	} else if !info.IsDir() {
+		add("Storage", "data_dir", checkFail, fmt.Sprintf("%s exists but is not a directory", dataDir), "")
+	} else {
+		add("Storage", "data_dir", checkOK, fmt.Sprintf("Data directory: %s", dataDir), "")
+	}
+
+	return checks
+}
+
 func firstLine(s string) string {
 	for i, c := range s {
 		if c == '\n' {
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/spf13/cobra"
 
@@ -12,7 +14,7 @@ import (
 var editCmd = &cobra.Command{
 	Use:   "edit <job>",
 	Short: "Edit a backup job",
-	Long:  "Interactive form to edit an existing backup job.",
+	Long:  "Edit every field of an existing backup job in a full-screen form, including adding, removing and reordering sources. Changes are shown as a diff before saving. With the same flags as 'keeper add' the job is edited non-interactively: only the flags given are changed and --source replaces the source list. For other fields use 'keeper job set'.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.Load()
@@ -25,12 +27,37 @@ var editCmd = &cobra.Command{
 			return fmt.Errorf("job %q not found", args[0])
 		}
 
-		updated, err := ui.RunAddJobForm(job)
-		if err != nil {
-			return err
+		var updated *config.Job
+		if jobFlagsGiven(cmd) {
+			copied := job.Clone()
+			updated = &copied
+			if err := applyJobFlags(cmd, updated); err != nil {
+				return err
+			}
+		} else {
+			// Validate in the form so mistakes can be fixed before the diff.
+			validate := func(j *config.Job) error {
+				candidate := *cfg
+				candidate.Jobs = slices.Clone(cfg.Jobs)
+				candidate.Jobs[idx] = *j
+				candidate.ApplyDefaults()
+				return candidate.Validate()
+			}
+			updated, err = ui.RunJobEditor("Edit Backup Job: "+job.Name, *job, validate)
+			if errors.Is(err, ui.ErrCancelled) {
+				fmt.Println(ui.Info("No changes saved"))
+				return nil
+			}
+			if err != nil {
+				return err
+			}
 		}
 
 		cfg.Jobs[idx] = *updated
+		cfg.ApplyDefaults()
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
 
 		if err := config.Save(cfg); err != nil {
 			return err
@@ -41,3 +68,7 @@ var editCmd = &cobra.Command{
 		return nil
 	},
 }
+
+func init() {
+	addJobFlags(editCmd)
+}
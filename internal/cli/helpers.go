@@ -1,10 +1,42 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
+
+	"github.com/klederson/keeper/internal/backup"
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+	"github.com/klederson/keeper/internal/ui"
 )
 
+// interruptContext returns a context cancelled on Ctrl+C or SIGTERM with an
+// interrupted abort reason, so a foreground run stops rsync and is still
+// recorded. Call stop once the run is over.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-sigs:
+			cancel(backup.NewAbortError(config.AbortInterrupted, "interrupted by "+sig.String()))
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigs)
+		cancel(nil)
+	}
+}
+
 func formatTimeAgo(t time.Time) string {
 	if t.IsZero() {
 		return "never"
@@ -29,6 +61,131 @@ func formatTimeAgo(t time.Time) string {
 	}
 }
 
+func formatTimeUntil(t time.Time) string {
+	if t.IsZero() {
+		return "—"
+	}
+
+	d := time.Until(t)
+
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("in %dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("in %dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("in %dd", int(d.Hours()/24))
+	default:
+		return t.Format("Jan 02 15:04")
+	}
+}
+
+// scheduleLabel summarises when a job runs: its cron expression or interval,
+// plus its time zone and run window when set.
+func scheduleLabel(job *config.Job) string {
+	label := job.Schedule
+	if job.Interval > 0 {
+		label = "every " + formatDuration(job.Interval)
+	}
+	if job.Trigger == config.TriggerWatch {
+		if label == "" {
+			label = "on change"
+		} else {
+			label += " + on change"
+		}
+	}
+	if job.Jitter > 0 {
+		label += " ~" + formatDuration(job.Jitter)
+	}
+	if job.Window != nil {
+		label += fmt.Sprintf(" [%s-%s]", job.Window.Start, job.Window.End)
+	}
+	if job.Timezone != "" {
+		label += " " + job.Timezone
+	}
+	return label
+}
+
+// sourcesLabel shows a job's first source and how many more there are.
+func sourcesLabel(job *config.Job) string {
+	if len(job.Sources) == 0 {
+		return ""
+	}
+	label := job.Sources[0].Path
+	if len(job.Sources) > 1 {
+		label += fmt.Sprintf(" (+%d more)", len(job.Sources)-1)
+	}
+	return label
+}
+
+// runStatus renders the outcome of a recorded run for tables.
+func runStatus(r config.RunRecord) string {
+	switch {
+	case r.DryRun:
+		return ui.MutedStyle.Render("~ dry-run")
+	case r.Status() == config.OutcomeWarning:
+		return ui.WarningStyle.Render("⚠ warning")
+	case r.Success:
+		return ui.AccentStyle.Render("✓ success")
+	case r.AbortReason == config.AbortTimeout:
+		return ui.ErrorStyle.Render("⏱ timed out")
+	case r.AbortReason == config.AbortStalled:
+		return ui.ErrorStyle.Render("⏱ stalled")
+	case r.AbortReason == config.AbortInterrupted:
+		return ui.WarningStyle.Render("⏹ interrupted")
+	case r.AbortReason == config.AbortWindowClosed:
+		return ui.WarningStyle.Render("⏸ window")
+	default:
+		return ui.ErrorStyle.Render("✗ failed")
+	}
+}
+
+// sizeLabel shows a run's total size, flagged with the change from the
+// job's baseline when the size was anomalous.
+func sizeLabel(r config.RunRecord) string {
+	label := reporter.FormatBytes(r.BytesTotal)
+	if len(r.Anomalies) == 0 {
+		return label
+	}
+	a := r.Anomalies[0]
+	arrow := "▲"
+	if a.Change < 0 {
+		arrow = "▼"
+	}
+	return ui.WarningStyle.Render(fmt.Sprintf("%s %s%.0f%%", label, arrow, math.Abs(a.Change)))
+}
+
+// rpoLabel shows how old a job's last successful backup is against its
+// max_age, or a dash when the job has none.
+func rpoLabel(job *config.Job, records []config.RunRecord, now time.Time) string {
+	rpo, ok := reporter.CheckRPO(job, records, now)
+	if !ok {
+		return ui.MutedStyle.Render("—")
+	}
+	if rpo.LastSuccess == nil {
+		return ui.ErrorStyle.Render("✗ none/" + formatAge(rpo.MaxAge))
+	}
+	label := formatAge(rpo.Age) + "/" + formatAge(rpo.MaxAge)
+	if rpo.Stale() {
+		return ui.ErrorStyle.Render("✗ " + label)
+	}
+	return ui.AccentStyle.Render("✓ " + label)
+}
+
+// formatAge renders a duration in its largest whole unit, e.g. 3d or 45m.
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
 func formatDuration(d time.Duration) string {
 	d = d.Round(time.Second)
 	if d < time.Minute {
@@ -44,15 +201,19 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dh %02dm", h, m)
 }
 
-func formatBytes(b int64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
+// parseSince turns a --since value into a point in time. It accepts a
+// number of days ("30d"), a Go duration ("12h") or a date ("2024-01-31").
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
 	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
 	}
-	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+	return time.Time{}, fmt.Errorf("invalid --since %q: use e.g. 30d, 12h or 2024-01-31", value)
 }
@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+	"github.com/klederson/keeper/internal/ui"
+)
+
+var (
+	historyRepair  bool
+	historyFormat  string
+	importFormat   string
+	historySince   string
+	historyJob     string
+	historyOut     string
+	historyRollups bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Maintain the run history",
+}
+
+var historyFsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check the run history for corruption",
+	Long:  "Scan every history segment for torn or invalid records. With --repair, damaged segments are rewritten with their valid records and the removed lines are kept in a .corrupt file beside them.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := reporter.NewStore().Fsck(historyRepair)
+		if err != nil {
+			return fmt.Errorf("checking history: %w", err)
+		}
+
+		fmt.Println(ui.Section("History Check"))
+		fmt.Println(ui.KeyValue([][2]string{
+			{"Segments", fmt.Sprintf("%d", report.Segments)},
+			{"Records", fmt.Sprintf("%d", report.Records)},
+			{"Problems", fmt.Sprintf("%d", len(report.Issues))},
+		}))
+
+		if len(report.Issues) == 0 {
+			fmt.Println(ui.Success("History is consistent"))
+			return nil
+		}
+
+		fmt.Println()
+		for _, issue := range report.Issues {
+			where := issue.Path
+			if issue.Line > 0 {
+				where = fmt.Sprintf("%s:%d", issue.Path, issue.Line)
+			}
+			fmt.Println("  " + ui.Warn(where+": "+issue.Problem))
+		}
+		fmt.Println()
+
+		if report.Repaired {
+			fmt.Println(ui.Success("Repaired; removed lines were saved with a .corrupt suffix"))
+			return nil
+		}
+		fmt.Println(ui.Info("Run 'keeper history fsck --repair' to fix these problems"))
+		return fmt.Errorf("history has %d problem(s)", len(report.Issues))
+	},
+}
+
+var historyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export run history as CSV or JSON",
+	Long:  "Write run records, oldest first, to stdout or --out. With --rollups, the daily aggregates of runs removed by retention are exported instead.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if historyFormat != "csv" && historyFormat != "json" {
+			return fmt.Errorf("unknown format %q: use csv or json", historyFormat)
+		}
+
+		var since time.Time
+		if historySince != "" {
+			t, err := parseSince(historySince, time.Now())
+			if err != nil {
+				return err
+			}
+			since = t
+		}
+
+		store := reporter.NewStore()
+		jobs := store.Jobs()
+		if historyJob != "" {
+			jobs = []string{historyJob}
+		}
+
+		var w io.Writer = os.Stdout
+		if historyOut != "" {
+			f, err := os.Create(historyOut)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if historyRollups {
+			var rollups []reporter.DailyRollup
+			for _, job := range jobs {
+				for _, r := range store.Rollups(job) {
+					if since.IsZero() || r.Date >= since.Format("2006-01-02") {
+						rollups = append(rollups, r)
+					}
+				}
+			}
+			if historyFormat == "json" {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+				return enc.Encode(rollups)
+			}
+			return reporter.WriteRollupsCSV(w, rollups)
+		}
+
+		var records []config.RunRecord
+		for _, r := range store.LoadAll() {
+			if historyJob != "" && r.JobName != historyJob {
+				continue
+			}
+			if !since.IsZero() && r.CompletedAt.Before(since) {
+				continue
+			}
+			records = append(records, r)
+		}
+
+		write := reporter.WriteCSV
+		if historyFormat == "json" {
+			write = reporter.WriteJSON
+		}
+		if err := write(w, records); err != nil {
+			return err
+		}
+		if historyOut != "" {
+			fmt.Println(ui.Success(fmt.Sprintf("Exported %d record(s) to %s", len(records), historyOut)))
+		}
+		return nil
+	},
+}
+
+var historyImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import run history from CSV or JSON",
+	Long:  "Add records from a file written by 'keeper history export'. Records already in history are skipped. The format is taken from the file extension unless --format is given.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := importFormat
+		if !cmd.Flags().Changed("format") {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(args[0])), ".")
+			if format == "jsonl" {
+				format = "json"
+			}
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		var records []config.RunRecord
+		switch format {
+		case "csv":
+			records, err = reporter.ReadCSV(f)
+		case "json":
+			records, err = reporter.ReadJSON(f)
+		default:
+			return fmt.Errorf("cannot tell the format of %s: use --format csv or --format json", args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", args[0], err)
+		}
+
+		added, err := reporter.NewStore().Import(records)
+		if err != nil {
+			return fmt.Errorf("importing history: %w", err)
+		}
+		fmt.Println(ui.Success(fmt.Sprintf("Imported %d record(s), skipped %d already in history", added, len(records)-added)))
+		return nil
+	},
+}
+
+func init() {
+	historyExportCmd.Flags().StringVar(&historyFormat, "format", "csv", "Output format: csv or json")
+	historyExportCmd.Flags().StringVar(&historySince, "since", "", "Only runs since this point (e.g. 30d, 12h, 2024-01-31)")
+	historyExportCmd.Flags().StringVar(&historyJob, "job", "", "Only runs of this job")
+	historyExportCmd.Flags().StringVarP(&historyOut, "out", "o", "", "Write to this file instead of stdout")
+	historyExportCmd.Flags().BoolVar(&historyRollups, "rollups", false, "Export daily roll-ups instead of individual runs")
+	historyCmd.AddCommand(historyExportCmd)
+
+	historyImportCmd.Flags().StringVar(&importFormat, "format", "", "Input format: csv or json (default: from the file extension)")
+	historyCmd.AddCommand(historyImportCmd)
+
+	historyFsckCmd.Flags().BoolVar(&historyRepair, "repair", false, "Rewrite damaged segments, keeping removed lines in .corrupt files")
+	historyCmd.AddCommand(historyFsckCmd)
+}
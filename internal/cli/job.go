@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/ui"
+)
+
+// Flags shared by add and edit. Edit applies only the ones given.
+var (
+	jobNameFlag  string
+	jobSources   []string
+	jobIncludes  []string
+	jobExcludes  []string
+	jobDest      string
+	jobSSHKey    string
+	jobPort      int
+	jobSchedule  string
+	jobInterval  time.Duration
+	jobBandwidth string
+	jobDelete    bool
+	jobCompress  bool
+)
+
+// jobFlagNames lists the flags that describe a job; giving any of them
+// skips the interactive form.
+var jobFlagNames = []string{
+	"name", "source", "include", "exclude", "dest", "ssh-key", "port",
+	"schedule", "interval", "bandwidth", "delete", "compress",
+}
+
+var jobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "Change backup jobs from scripts",
+}
+
+var jobSetCmd = &cobra.Command{
+	Use:   "set <job> <key=value>...",
+	Short: "Set fields of a backup job",
+	Long: `Set fields of a backup job without opening a form. Keys are the YAML keys
+of the job, with dots for nesting and numbers for list items:
+
+  keeper job set docs destination.host=nas.local schedule="0 3 * * *"
+  keeper job set docs sources.1.path=/home/user/Pictures delete=true
+  keeper job set docs window.start=22:00 window.end=06:00
+
+Values are read as YAML; an empty value resets the field, and destination
+fields reset this way follow the config's defaults again. The result is
+validated before it is saved.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		job, idx := cfg.FindJob(args[0])
+		if job == nil {
+			return fmt.Errorf("job %q not found", args[0])
+		}
+
+		updated := *job
+		for _, arg := range args[1:] {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("invalid assignment %q: use key=value", arg)
+			}
+			if err := updated.Set(key, value); err != nil {
+				return err
+			}
+		}
+
+		cfg.Jobs[idx] = updated
+		cfg.ApplyDefaults()
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+
+		fmt.Println(ui.Success(fmt.Sprintf("Job %q updated", updated.Name)))
+		return nil
+	},
+}
+
+func init() {
+	jobCmd.AddCommand(jobSetCmd)
+}
+
+// addJobFlags registers the job flags on add or edit.
+func addJobFlags(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.StringVar(&jobNameFlag, "name", "", "Job name")
+	f.StringArrayVar(&jobSources, "source", nil, "Source path (repeatable)")
+	f.StringArrayVar(&jobIncludes, "include", nil, "Include pattern for every source (repeatable)")
+	f.StringArrayVar(&jobExcludes, "exclude", nil, "Exclude pattern for every source (repeatable)")
+	f.StringVar(&jobDest, "dest", "", "Destination as [user@]host:/path")
+	f.StringVar(&jobSSHKey, "ssh-key", "", "SSH key for the destination")
+	f.IntVar(&jobPort, "port", 22, "SSH port of the destination")
+	f.StringVar(&jobSchedule, "schedule", "", "Cron schedule, e.g. \"0 2 * * *\" or @daily")
+	f.DurationVar(&jobInterval, "interval", 0, "Run every interval instead of on a cron schedule")
+	f.StringVar(&jobBandwidth, "bandwidth", "", "Bandwidth limit, e.g. 500k (0 = unlimited)")
+	f.BoolVar(&jobDelete, "delete", false, "Delete files on the destination that were removed from the source")
+	f.BoolVar(&jobCompress, "compress", true, "Compress during transfer (rsync -z)")
+}
+
+// jobFlagsGiven reports whether any job flag was set on the command line.
+func jobFlagsGiven(cmd *cobra.Command) bool {
+	return slices.ContainsFunc(jobFlagNames, cmd.Flags().Changed)
+}
+
+// applyJobFlags copies the job flags that were given onto job. Sources
+// already on the job keep their patterns unless --include or --exclude
+// replace them.
+func applyJobFlags(cmd *cobra.Command, job *config.Job) error {
+	f := cmd.Flags()
+
+	if f.Changed("name") {
+		job.Name = jobNameFlag
+	}
+	if f.Changed("source") {
+		sources := make([]config.Source, 0, len(jobSources))
+		for _, path := range jobSources {
+			src := config.Source{Path: path}
+			for _, existing := range job.Sources {
+				if existing.Path == path {
+					src = existing
+				}
+			}
+			sources = append(sources, src)
+		}
+		job.Sources = sources
+	}
+	for i := range job.Sources {
+		if f.Changed("include") {
+			job.Sources[i].Include = jobIncludes
+		}
+		if f.Changed("exclude") {
+			job.Sources[i].Exclude = jobExcludes
+		}
+	}
+
+	if f.Changed("dest") {
+		user, host, path, err := parseDest(jobDest)
+		if err != nil {
+			return err
+		}
+		job.Destination.User, job.Destination.Host, job.Destination.Path = user, host, path
+		if job.Destination.Type == "" {
+			job.Destination.Type = "rsync"
+		}
+	}
+	if f.Changed("ssh-key") {
+		job.Destination.SSHKey = jobSSHKey
+	}
+	if f.Changed("port") {
+		job.Destination.Port = jobPort
+	}
+
+	if f.Changed("schedule") && f.Changed("interval") {
+		return fmt.Errorf("use either --schedule or --interval, not both")
+	}
+	if f.Changed("schedule") {
+		job.Schedule, job.Interval = jobSchedule, 0
+	}
+	if f.Changed("interval") {
+		job.Schedule, job.Interval = "", jobInterval
+	}
+	if f.Changed("bandwidth") {
+		job.Bandwidth = jobBandwidth
+	}
+	if f.Changed("delete") {
+		job.Delete = jobDelete
+	}
+	if f.Changed("compress") {
+		job.Compress = jobCompress
+	}
+	return nil
+}
+
+// parseDest splits an rsync-style [user@]host:/path destination.
+func parseDest(dest string) (user, host, path string, err error) {
+	hostPart, path, ok := strings.Cut(dest, ":")
+	if !ok || hostPart == "" || path == "" {
+		return "", "", "", fmt.Errorf("invalid --dest %q: use [user@]host:/path", dest)
+	}
+	if u, h, ok := strings.Cut(hostPart, "@"); ok {
+		user, hostPart = u, h
+	}
+	return user, hostPart, path, nil
+}
+
+// readJobFile reads a job definition from a YAML file, or from stdin when
+// path is "-".
+func readJobFile(path string) (*config.Job, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := config.ParseJob(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return job, nil
+}
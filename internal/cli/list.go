@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -20,6 +21,15 @@ var listCmd = &cobra.Command{
 			return err
 		}
 
+		if structuredOutput() {
+			store, pauses, now := reporter.NewStore(), loadPauses(), time.Now()
+			jobs := make([]jobOutput, 0, len(cfg.Jobs))
+			for i := range cfg.Jobs {
+				jobs = append(jobs, newJobOutput(&cfg.Jobs[i], store, pauses, now))
+			}
+			return printOutput(cmd, listOutput{Jobs: jobs})
+		}
+
 		if len(cfg.Jobs) == 0 {
 			fmt.Println(ui.Info("No backup jobs configured"))
 			fmt.Println(ui.Info("Run 'keeper add' to create one"))
@@ -27,6 +37,7 @@ var listCmd = &cobra.Command{
 		}
 
 		store := reporter.NewStore()
+		pauses := loadPauses()
 
 		columns := []ui.TableColumn{
 			{Title: "Name", Width: 18},
@@ -34,6 +45,7 @@ var listCmd = &cobra.Command{
 			{Title: "Destination", Width: 30},
 			{Title: "Schedule", Width: 16},
 			{Title: "Last Run", Width: 14},
+			{Title: "State", Width: 14},
 		}
 
 		rows := make([][]string, 0, len(cfg.Jobs))
@@ -68,12 +80,18 @@ var listCmd = &cobra.Command{
 				lastRun = icon + " " + formatTimeAgo(r.CompletedAt)
 			}
 
+			state := pauseLabel(pauses, job.Name)
+			if state == "" {
+				state = ui.AccentStyle.Render("active")
+			}
+
 			rows = append(rows, []string{
 				job.Name,
 				source,
 				dest,
-				job.Schedule,
+				scheduleLabel(&job),
 				lastRun,
+				state,
 			})
 		}
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/klederson/keeper/internal/backend"
 	"github.com/klederson/keeper/internal/config"
 	"github.com/klederson/keeper/internal/reporter"
 	"github.com/klederson/keeper/internal/ui"
@@ -31,9 +32,20 @@ var logsCmd = &cobra.Command{
 			if job, _ := cfg.FindJob(jobName); job == nil {
 				return fmt.Errorf("job %q not found", jobName)
 			}
+			if structuredOutput() {
+				records := store.GetJobRecords(jobName, 20)
+				out := logsOutput{Job: jobName, Runs: nonNil(records)}
+				if len(records) > 0 {
+					out.Hints = backend.Hints(records[0].TypedErrors)
+				}
+				return printOutput(cmd, out)
+			}
 			return showJobLogs(store, jobName)
 		}
 
+		if structuredOutput() {
+			return printOutput(cmd, logsOutput{Runs: nonNil(store.GetRecentRecords(30))})
+		}
 		return showAllLogs(store)
 	},
 }
@@ -51,41 +63,55 @@ func showJobLogs(store *reporter.Store, jobName string) error {
 	columns := []ui.TableColumn{
 		{Title: "Date", Width: 20},
 		{Title: "Status", Width: 12},
+		{Title: "Trigger", Width: 10},
 		{Title: "Duration", Width: 10},
 		{Title: "Files", Width: 8},
 		{Title: "Transferred", Width: 14},
-		{Title: "Errors", Width: 24},
+		{Title: "Errors/Warnings", Width: 24},
 	}
 
 	rows := make([][]string, 0, len(records))
 	for _, r := range records {
-		status := ui.AccentStyle.Render("✓ success")
-		if !r.Success {
-			status = ui.ErrorStyle.Render("✗ failed")
-		}
-		if r.DryRun {
-			status = ui.MutedStyle.Render("~ dry-run")
-		}
+		status := runStatus(r)
 
 		errMsg := ""
-		if len(r.Errors) > 0 {
+		if len(r.TypedErrors) > 0 {
+			errMsg = backend.ErrorLabel(r.TypedErrors[0].Kind)
+		} else if len(r.Errors) > 0 {
 			errMsg = r.Errors[0]
-			if len(errMsg) > 22 {
-				errMsg = errMsg[:22] + "..."
-			}
+		} else if len(r.Warnings) > 0 {
+			errMsg = r.Warnings[0]
+		}
+		if len(errMsg) > 22 {
+			errMsg = errMsg[:22] + "..."
+		}
+
+		trigger := r.Trigger
+		if trigger == "" {
+			trigger = ui.MutedStyle.Render("—")
 		}
 
 		rows = append(rows, []string{
 			r.StartedAt.Format(time.DateTime),
 			status,
+			trigger,
 			formatDuration(r.CompletedAt.Sub(r.StartedAt)),
 			fmt.Sprintf("%d", r.FilesTransferred),
-			formatBytes(r.BytesTransferred),
+			reporter.FormatBytes(r.BytesTransferred),
 			errMsg,
 		})
 	}
 
 	fmt.Println(ui.Table(columns, rows))
+
+	// Hints apply to the latest run; older problems may already be fixed.
+	if latest := records[0]; len(latest.TypedErrors) > 0 {
+		fmt.Println(ui.Section("Suggested Fixes"))
+		for _, hint := range backend.Hints(latest.TypedErrors) {
+			fmt.Println("  " + ui.Info(hint))
+		}
+		fmt.Println()
+	}
 	return nil
 }
 
@@ -100,7 +126,7 @@ func showAllLogs(store *reporter.Store) error {
 	fmt.Println(ui.Section("Recent Activity"))
 
 	for _, r := range records {
-		icon := ui.StatusIcon(r.Success)
+		icon := ui.OutcomeIcon(r.Status())
 		if r.DryRun {
 			icon = ui.MutedStyle.Render("~")
 		}
@@ -111,7 +137,12 @@ func showAllLogs(store *reporter.Store) error {
 
 		if r.Success {
 			duration := formatDuration(r.CompletedAt.Sub(r.StartedAt))
-			detail = ui.TextStyle.Render(fmt.Sprintf("%s in %s", formatBytes(r.BytesTransferred), duration))
+			detail = ui.TextStyle.Render(fmt.Sprintf("%s in %s", reporter.FormatBytes(r.BytesTransferred), duration))
+			if len(r.Warnings) > 0 {
+				detail += " " + ui.WarningStyle.Render(r.Warnings[0])
+			}
+		} else if len(r.TypedErrors) > 0 {
+			detail = ui.ErrorStyle.Render(backend.ErrorLabel(r.TypedErrors[0].Kind)) + " " + ui.MutedStyle.Render(backend.Hint(r.TypedErrors[0]))
 		} else if len(r.Errors) > 0 {
 			detail = ui.ErrorStyle.Render(r.Errors[0])
 		}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/notify"
+	"github.com/klederson/keeper/internal/reporter"
+	"github.com/klederson/keeper/internal/ui"
+)
+
+var notifyDigest bool
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage notification targets",
+}
+
+var notifyListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List notification targets",
+	Aliases: []string{"ls"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		if len(cfg.Notifications.Targets) == 0 {
+			fmt.Println(ui.Info("No notification targets configured"))
+			return nil
+		}
+
+		columns := []ui.TableColumn{
+			{Title: "Name", Width: 18},
+			{Title: "Type", Width: 10},
+			{Title: "Jobs", Width: 24},
+			{Title: "On", Width: 18},
+			{Title: "Digest", Width: 14},
+		}
+
+		rows := make([][]string, 0, len(cfg.Notifications.Targets))
+		for _, t := range cfg.Notifications.Targets {
+			digest := ui.MutedStyle.Render("-")
+			if t.Digest != nil {
+				digest = t.Digest.Schedule
+			}
+			rows = append(rows, []string{t.Name, t.Type, joinOrAll(t.Jobs), joinOrAll(t.Outcomes()), digest})
+		}
+
+		fmt.Println(ui.Section("Notification Targets"))
+		fmt.Println(ui.Table(columns, rows))
+		return nil
+	},
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test <target>",
+	Short: "Send a sample notification to a target",
+	Long:  "Send a sample notification to a target. With --digest, send the target's digest for the current period instead.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		target := cfg.FindNotifyTarget(args[0])
+		if target == nil {
+			return fmt.Errorf("notification target %q not found", args[0])
+		}
+
+		n, err := notify.New(*target)
+		if err != nil {
+			return err
+		}
+
+		if notifyDigest {
+			dg := notify.DigestFor(*target, cfg.Jobs, reporter.NewStore().LoadAll(), time.Now())
+			fmt.Println(ui.Info(fmt.Sprintf("Sending digest to %q...", target.Name)))
+			if err := notify.SendDigest(context.Background(), *target, n, dg); err != nil {
+				return fmt.Errorf("sending to %q: %w", target.Name, err)
+			}
+			fmt.Println(ui.Success("Digest delivered"))
+			return nil
+		}
+
+		fmt.Println(ui.Info(fmt.Sprintf("Sending test notification to %q...", target.Name)))
+		if err := notify.Send(context.Background(), *target, n, notify.SampleEvent()); err != nil {
+			return fmt.Errorf("sending to %q: %w", target.Name, err)
+		}
+
+		fmt.Println(ui.Success("Test notification delivered"))
+		return nil
+	},
+}
+
+func init() {
+	notifyTestCmd.Flags().BoolVar(&notifyDigest, "digest", false, "Send the digest instead of a sample alert")
+	notifyCmd.AddCommand(notifyListCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+}
+
+func joinOrAll(values []string) string {
+	if len(values) == 0 {
+		return ui.MutedStyle.Render("all")
+	}
+	out := values[0]
+	for _, v := range values[1:] {
+		out += ", " + v
+	}
+	return out
+}
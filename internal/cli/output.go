@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/pause"
+	"github.com/klederson/keeper/internal/reporter"
+	"github.com/klederson/keeper/internal/scheduler"
+	"github.com/klederson/keeper/internal/ui"
+)
+
+// outputVersion is the schema version of --output json|yaml. Bump it when a
+// field is renamed, removed or changes meaning; adding fields doesn't.
+const outputVersion = 1
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+var (
+	outputFormat string
+	noColor      bool
+)
+
+// envelope wraps every structured result. Data is the command's payload;
+// Error is set instead when the command failed.
+type envelope struct {
+	Version int          `json:"version"`
+	Command string       `json:"command"`
+	Data    any          `json:"data,omitempty"`
+	Error   *errorOutput `json:"error,omitempty"`
+}
+
+type errorOutput struct {
+	Message string `json:"message"`
+}
+
+// jobOutput describes a configured job and its latest run for list and
+// status. Durations are in seconds.
+type jobOutput struct {
+	Name         string            `json:"name"`
+	Sources      []string          `json:"sources"`
+	Destination  string            `json:"destination"`
+	Schedule     string            `json:"schedule,omitempty"`
+	IntervalSecs float64           `json:"interval_seconds,omitempty"`
+	Trigger      string            `json:"trigger,omitempty"`
+	Paused       bool              `json:"paused"`
+	PausedUntil  *time.Time        `json:"paused_until,omitempty"`
+	NextRun      *time.Time        `json:"next_run,omitempty"`
+	LastRun      *config.RunRecord `json:"last_run,omitempty"`
+}
+
+// rpoOutput is a job's recovery-point state; it is omitted for jobs
+// without max_age.
+type rpoOutput struct {
+	MaxAgeSecs  float64    `json:"max_age_seconds"`
+	AgeSecs     float64    `json:"age_seconds,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Stale       bool       `json:"stale"`
+}
+
+type listOutput struct {
+	Jobs []jobOutput `json:"jobs"`
+}
+
+type jobStatusOutput struct {
+	jobOutput
+	RPO *rpoOutput `json:"rpo,omitempty"`
+}
+
+type statusOutput struct {
+	Period       string            `json:"period"`
+	TotalRuns    int               `json:"total_runs"`
+	SuccessCount int               `json:"success_count"`
+	WarningCount int               `json:"warning_count"`
+	FailCount    int               `json:"fail_count"`
+	SuccessRate  float64           `json:"success_rate"`
+	TotalBytes   int64             `json:"total_bytes"`
+	AvgDuration  float64           `json:"avg_duration_seconds"`
+	Jobs         []jobStatusOutput `json:"jobs"`
+}
+
+// logsOutput is the recent runs of one job, or of every job when Job is
+// empty, newest first. Hints are suggested fixes for the latest run.
+type logsOutput struct {
+	Job   string             `json:"job,omitempty"`
+	Runs  []config.RunRecord `json:"runs"`
+	Hints []string           `json:"hints,omitempty"`
+}
+
+// runOutput is the result of run or test: one record per job run.
+type runOutput struct {
+	Runs []config.RunRecord `json:"runs"`
+}
+
+type doctorOutput struct {
+	OK     bool          `json:"ok"`
+	Checks []doctorCheck `json:"checks"`
+}
+
+// structuredOutput reports whether --output asks for JSON or YAML.
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// setupOutput validates --output and turns off styling when it isn't
+// wanted. It runs before every command.
+func setupOutput(cmd *cobra.Command, args []string) error {
+	switch outputFormat {
+	case outputText, outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("unknown output format %q: use text, json or yaml", outputFormat)
+	}
+	if noColor || os.Getenv("NO_COLOR") != "" || structuredOutput() {
+		ui.DisableColor()
+	}
+	cmd.SilenceUsage = structuredOutput()
+	return nil
+}
+
+// printOutput writes data as the command's structured result.
+func printOutput(cmd *cobra.Command, data any) error {
+	return writeEnvelope(os.Stdout, envelope{Version: outputVersion, Command: commandName(cmd), Data: data})
+}
+
+// printError writes a failed command's error in the structured format.
+func printError(cmd *cobra.Command, err error) {
+	writeEnvelope(os.Stdout, envelope{Version: outputVersion, Command: commandName(cmd), Error: &errorOutput{Message: err.Error()}})
+}
+
+func writeEnvelope(w io.Writer, env envelope) error {
+	if outputFormat == outputYAML {
+		// Round-trip through JSON so YAML keys match the JSON field names.
+		b, err := json.Marshal(env)
+		if err != nil {
+			return err
+		}
+		var v any
+		if err := json.Unmarshal(b, &v); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(env)
+}
+
+// nonNil keeps empty lists as [] rather than null in structured output.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// commandName is the command path without the program name, e.g. "status".
+func commandName(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" ")
+}
+
+// notice prints a side message. With structured output it goes to stderr
+// so stdout stays parseable.
+func notice(msg string) {
+	if structuredOutput() {
+		fmt.Fprintln(os.Stderr, msg)
+		return
+	}
+	fmt.Println(msg)
+}
+
+func newJobOutput(job *config.Job, store *reporter.Store, pauses pause.State, now time.Time) jobOutput {
+	out := jobOutput{
+		Name: job.Name,
+		Destination: fmt.Sprintf("%s@%s:%s",
+			job.Destination.User, job.Destination.Host, job.Destination.Path),
+		Schedule:     job.Schedule,
+		IntervalSecs: job.Interval.Seconds(),
+		Trigger:      job.Trigger,
+		Sources:      make([]string, 0, len(job.Sources)),
+	}
+	for _, src := range job.Sources {
+		out.Sources = append(out.Sources, src.Path)
+	}
+	if e, ok := pauses.Paused(job.Name, now); ok {
+		out.Paused = true
+		if !e.Until.IsZero() {
+			out.PausedUntil = &e.Until
+		}
+	} else if next := scheduler.NextRun(job, now); !next.IsZero() {
+		out.NextRun = &next
+	}
+	if records := store.GetJobRecords(job.Name, 1); len(records) > 0 {
+		out.LastRun = &records[0]
+	}
+	return out
+}
+
+func newRPOOutput(job *config.Job, records []config.RunRecord, now time.Time) *rpoOutput {
+	rpo, ok := reporter.CheckRPO(job, records, now)
+	if !ok {
+		return nil
+	}
+	out := &rpoOutput{MaxAgeSecs: rpo.MaxAge.Seconds(), Stale: rpo.Stale()}
+	if rpo.LastSuccess != nil {
+		out.AgeSecs = rpo.Age.Seconds()
+		out.LastSuccess = &rpo.LastSuccess.CompletedAt
+	}
+	return out
+}
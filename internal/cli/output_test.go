@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// The envelope is a scripting contract: these tests pin its field names and
+// version so a change to either is deliberate.
+
+func withOutputFormat(t *testing.T, format string) {
+	t.Helper()
+	prev := outputFormat
+	outputFormat = format
+	t.Cleanup(func() { outputFormat = prev })
+}
+
+// captureStdout returns what fn writes to stdout.
+func captureStdout(t *testing.T, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func decodeEnvelope(t *testing.T, format string, data []byte) map[string]any {
+	t.Helper()
+	var env map[string]any
+	var err error
+	if format == outputYAML {
+		err = yaml.Unmarshal(data, &env)
+	} else {
+		err = json.Unmarshal(data, &env)
+	}
+	if err != nil {
+		t.Fatalf("decoding %s envelope: %v\n%s", format, err, data)
+	}
+	return env
+}
+
+func sortedKeys(m map[string]any) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+func TestOutputVersion(t *testing.T) {
+	if outputVersion != 1 {
+		t.Errorf("outputVersion = %d; update this test only with a deliberate schema change", outputVersion)
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	if got := commandName(historyExportCmd); got != "history export" {
+		t.Errorf("commandName(history export) = %q", got)
+	}
+	if got := commandName(statusCmd); got != "status" {
+		t.Errorf("commandName(status) = %q", got)
+	}
+}
+
+func TestDataEnvelope(t *testing.T) {
+	for _, format := range []string{outputJSON, outputYAML} {
+		withOutputFormat(t, format)
+		out := captureStdout(t, func() {
+			if err := printOutput(statusCmd, runOutput{Runs: []config.RunRecord{{JobName: "web", Success: true}}}); err != nil {
+				t.Errorf("%s: printOutput: %v", format, err)
+			}
+		})
+
+		env := decodeEnvelope(t, format, out)
+		if keys := sortedKeys(env); !slices.Equal(keys, []string{"command", "data", "version"}) {
+			t.Errorf("%s: envelope keys = %v", format, keys)
+		}
+		if fmt.Sprint(env["version"]) != "1" {
+			t.Errorf("%s: version = %v", format, env["version"])
+		}
+		if env["command"] != "status" {
+			t.Errorf("%s: command = %v", format, env["command"])
+		}
+
+		// YAML uses the JSON field names, not Go's lowercased ones.
+		data, _ := env["data"].(map[string]any)
+		runs, _ := data["runs"].([]any)
+		if len(runs) != 1 {
+			t.Fatalf("%s: data = %v", format, env["data"])
+		}
+		if run, _ := runs[0].(map[string]any); run["job_name"] != "web" {
+			t.Errorf("%s: run = %v", format, run)
+		}
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	for _, format := range []string{outputJSON, outputYAML} {
+		withOutputFormat(t, format)
+		out := captureStdout(t, func() {
+			printError(historyExportCmd, errors.New("history is locked"))
+		})
+
+		env := decodeEnvelope(t, format, out)
+		if keys := sortedKeys(env); !slices.Equal(keys, []string{"command", "error", "version"}) {
+			t.Errorf("%s: envelope keys = %v", format, keys)
+		}
+		if env["command"] != "history export" {
+			t.Errorf("%s: command = %v", format, env["command"])
+		}
+		errOut, _ := env["error"].(map[string]any)
+		if keys := sortedKeys(errOut); !slices.Equal(keys, []string{"message"}) || errOut["message"] != "history is locked" {
+			t.Errorf("%s: error = %v", format, env["error"])
+		}
+	}
+}
+
+func TestWriteEnvelopeJSONIndent(t *testing.T) {
+	withOutputFormat(t, outputJSON)
+	var buf bytes.Buffer
+	if err := writeEnvelope(&buf, envelope{Version: outputVersion, Command: "list", Data: listOutput{Jobs: []jobOutput{}}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"version\": 1,\n  \"command\": \"list\",\n  \"data\": {\n    \"jobs\": []\n  }\n}\n"
+	if buf.String() != want {
+		t.Errorf("JSON envelope =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/pause"
+	"github.com/klederson/keeper/internal/ui"
+)
+
+var (
+	pauseAll  bool
+	pauseFor  time.Duration
+	resumeAll bool
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause [job]",
+	Short: "Pause scheduled runs of a job",
+	Long:  "Stop the daemon from starting a job (or all jobs with --all) until resumed or until --for elapses. The schedule in config.yaml is left untouched.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jobName, err := pauseTarget(args, pauseAll)
+		if err != nil {
+			return err
+		}
+
+		var until time.Time
+		if pauseFor > 0 {
+			until = time.Now().Add(pauseFor)
+		}
+
+		if err := pause.NewStore().Pause(jobName, until); err != nil {
+			return err
+		}
+
+		target := fmt.Sprintf("Job %q", jobName)
+		if jobName == "" {
+			target = "All jobs"
+		}
+		if until.IsZero() {
+			fmt.Println(ui.Success(target + " paused until 'keeper resume'"))
+		} else {
+			fmt.Println(ui.Success(fmt.Sprintf("%s paused until %s", target, until.Format("Jan 02 15:04"))))
+		}
+		return nil
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [job]",
+	Short: "Resume a paused job",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jobName, err := pauseTarget(args, resumeAll)
+		if err != nil {
+			return err
+		}
+
+		err = pause.NewStore().Resume(jobName)
+		if errors.Is(err, pause.ErrAllPaused) {
+			return fmt.Errorf("all jobs are paused; run 'keeper resume --all' to resume them")
+		}
+		if err != nil {
+			return err
+		}
+
+		if jobName == "" {
+			fmt.Println(ui.Success("All jobs resumed"))
+		} else {
+			fmt.Println(ui.Success(fmt.Sprintf("Job %q resumed", jobName)))
+		}
+		return nil
+	},
+}
+
+func init() {
+	pauseCmd.Flags().BoolVar(&pauseAll, "all", false, "Pause all jobs")
+	pauseCmd.Flags().DurationVar(&pauseFor, "for", 0, "Pause only for this long (e.g. 2h)")
+	resumeCmd.Flags().BoolVar(&resumeAll, "all", false, "Resume all jobs")
+}
+
+// pauseTarget resolves the job argument, returning "" for --all.
+func pauseTarget(args []string, all bool) (string, error) {
+	if all {
+		if len(args) > 0 {
+			return "", fmt.Errorf("specify a job name or --all, not both")
+		}
+		return "", nil
+	}
+	if len(args) == 0 {
+		return "", fmt.Errorf("specify a job name or use --all")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	if job, _ := cfg.FindJob(args[0]); job == nil {
+		return "", fmt.Errorf("job %q not found", args[0])
+	}
+	return args[0], nil
+}
+
+// loadPauses reads the pause state, treating an unreadable file as "nothing
+// paused" so read-only commands keep working.
+func loadPauses() pause.State {
+	st, err := pause.NewStore().Load()
+	if err != nil {
+		notice(ui.Warn(err.Error()))
+	}
+	return st
+}
+
+// pauseLabel describes a job's pause for tables, or "" when it isn't paused.
+func pauseLabel(st pause.State, jobName string) string {
+	e, ok := st.Paused(jobName, time.Now())
+	if !ok {
+		return ""
+	}
+	if e.Until.IsZero() {
+		return ui.WarningStyle.Render("⏸ paused")
+	}
+	return ui.WarningStyle.Render("⏸ " + formatDuration(time.Until(e.Until)) + " left")
+}
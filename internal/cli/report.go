@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+	"github.com/klederson/keeper/internal/ui"
+)
+
+var (
+	reportSince  string
+	reportFormat string
+	reportOut    string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate a backup report",
+	Long:  "Summarise every job over a period: success rates, duration percentiles, data volume and its trend, failure streaks and the most common error causes. The HTML report is a single self-contained file with inline charts.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch reportFormat {
+		case reporter.ReportHTML, reporter.ReportMarkdown, reporter.ReportJSON:
+		default:
+			return fmt.Errorf("unknown format %q: use html, md or json", reportFormat)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		since, err := parseSince(reportSince, now)
+		if err != nil {
+			return err
+		}
+
+		jobs := make([]string, 0, len(cfg.Jobs))
+		for _, job := range cfg.Jobs {
+			jobs = append(jobs, job.Name)
+		}
+		rep := reporter.BuildReport(jobs, reporter.NewStore().LoadAll(), since, now)
+
+		var w io.Writer = os.Stdout
+		if reportOut != "" {
+			f, err := os.Create(reportOut)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if err := reporter.WriteReport(w, rep, reportFormat); err != nil {
+			return err
+		}
+		if reportOut != "" {
+			fmt.Println(ui.Success("Report written to " + reportOut))
+		}
+		return nil
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportSince, "since", "30d", "Period to cover (e.g. 30d, 12h, 2024-01-31)")
+	reportCmd.Flags().StringVar(&reportFormat, "format", reporter.ReportMarkdown, "Output format: html, md or json")
+	reportCmd.Flags().StringVarP(&reportOut, "out", "o", "", "Write to this file instead of stdout")
+}
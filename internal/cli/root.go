@@ -7,35 +7,58 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/klederson/keeper/internal/ui"
+	"github.com/klederson/keeper/internal/version"
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "keeper",
-	Short: "Backup daemon & CLI tool",
-	Long:  ui.Banner(),
+	Use:     "keeper",
+	Short:   "Backup daemon & CLI tool",
+	Long:    ui.Banner(),
+	Version: version.Version,
+	// Execute reports errors itself, in the format --output asks for.
+	SilenceErrors:     true,
+	PersistentPreRunE: setupOutput,
 	CompletionOptions: cobra.CompletionOptions{
 		HiddenDefaultCmd: true,
 	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil {
+		return
+	}
+	if structuredOutput() {
+		printError(cmd, err)
+	} else {
 		fmt.Fprintln(os.Stderr, ui.Error(err.Error()))
-		os.Exit(1)
 	}
+	os.Exit(1)
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputText, "Output format for list, status, logs, run, test and doctor: text, json or yaml")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colours and styling")
+
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(jobCmd)
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
 	rootCmd.AddCommand(testCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(scheduleCmd)
 	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(daemonCmd)
+	rootCmd.AddCommand(notifyCmd)
 	rootCmd.AddCommand(dashboardCmd)
 	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(configCmd)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/klederson/keeper/internal/backend"
 	"github.com/klederson/keeper/internal/backup"
 	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/notify"
 	"github.com/klederson/keeper/internal/reporter"
 	"github.com/klederson/keeper/internal/ui"
 )
@@ -31,20 +32,53 @@ var runCmd = &cobra.Command{
 
 		orch := backup.NewOrchestrator()
 		store := reporter.NewStore()
+		notifier := notify.NewDispatcher(cfg)
 		ctx := context.Background()
+		runCtx, stop := interruptContext()
+		defer stop()
 
 		if runAll {
-			fmt.Println(ui.Info(fmt.Sprintf("Running all %d jobs...", len(cfg.Jobs))))
-			fmt.Println()
+			pauses := loadPauses()
+			jobs := make([]config.Job, 0, len(cfg.Jobs))
+			for _, job := range cfg.Jobs {
+				if _, paused := pauses.Paused(job.Name, time.Now()); paused {
+					notice(ui.Warn(fmt.Sprintf("Skipping paused job %q", job.Name)))
+					continue
+				}
+				jobs = append(jobs, job)
+			}
+
+			if !structuredOutput() {
+				fmt.Println(ui.Info(fmt.Sprintf("Running all %d jobs...", len(jobs))))
+				fmt.Println()
+			}
 
-			results := orch.RunAll(ctx, cfg.Jobs, false, func(jobName string, evt backend.ProgressEvent) {
+			results := orch.RunAll(runCtx, jobs, false, func(jobName string, evt backend.ProgressEvent) {
 				printProgress(jobName, evt)
 			})
-			for name, result := range results {
+			runs := make([]config.RunRecord, 0, len(results))
+			for _, job := range jobs {
+				name := job.Name
+				result, ok := results[name]
+				if !ok {
+					continue
+				}
 				clearProgress()
-				backup.PrintResult(name, result, false)
+				if !structuredOutput() {
+					backup.PrintResult(name, result, false)
+				}
 				record := reporter.ResultToRecord(name, result, false)
-				store.Append(record)
+				record.Trigger = config.TriggerManual
+				store.FlagAnomalies(&job, &record)
+				printAnomalies(record)
+				if err := store.Append(record); err != nil {
+					notice(ui.Warn(fmt.Sprintf("Run of %s not recorded: %v", name, err)))
+				}
+				notifier.DispatchRun(ctx, record)
+				runs = append(runs, record)
+			}
+			if structuredOutput() {
+				return printOutput(cmd, runOutput{Runs: runs})
 			}
 			return nil
 		}
@@ -59,9 +93,13 @@ var runCmd = &cobra.Command{
 			return fmt.Errorf("job %q not found", jobName)
 		}
 
+		if _, paused := loadPauses().Paused(jobName, time.Now()); paused {
+			notice(ui.Warn(fmt.Sprintf("Job %q is paused — running anyway because it was requested explicitly", jobName)))
+		}
+
 		printJobHeader(job)
 
-		result, err := orch.Run(ctx, job, false, func(evt backend.ProgressEvent) {
+		result, err := orch.Run(runCtx, job, false, func(evt backend.ProgressEvent) {
 			printProgress(jobName, evt)
 		})
 		clearProgress()
@@ -70,11 +108,22 @@ var runCmd = &cobra.Command{
 			return err
 		}
 
-		backup.PrintResult(jobName, result, false)
+		if !structuredOutput() {
+			backup.PrintResult(jobName, result, false)
+		}
 
 		record := reporter.ResultToRecord(jobName, result, false)
-		store.Append(record)
+		record.Trigger = config.TriggerManual
+		store.FlagAnomalies(job, &record)
+		printAnomalies(record)
+		if err := store.Append(record); err != nil {
+			notice(ui.Warn(fmt.Sprintf("Run not recorded: %v", err)))
+		}
+		notifier.DispatchRun(ctx, record)
 
+		if structuredOutput() {
+			return printOutput(cmd, runOutput{Runs: []config.RunRecord{record}})
+		}
 		return nil
 	},
 }
@@ -83,18 +132,26 @@ func init() {
 	runCmd.Flags().BoolVar(&runAll, "all", false, "Run all backup jobs")
 }
 
+// printAnomalies warns about a run whose size was unusual for its job.
+func printAnomalies(record config.RunRecord) {
+	if structuredOutput() {
+		return
+	}
+	for _, a := range record.Anomalies {
+		fmt.Println("  " + ui.Warn("Unusual size: "+notify.DescribeAnomaly(a)))
+	}
+}
+
+// printJobHeader, printProgress and clearProgress print nothing with
+// structured output, where only the final result goes to stdout.
 func printJobHeader(job *config.Job) {
-	src := ""
-	if len(job.Sources) > 0 {
-		src = job.Sources[0].Path
-		if len(job.Sources) > 1 {
-			src += fmt.Sprintf(" (+%d more)", len(job.Sources)-1)
-		}
+	if structuredOutput() {
+		return
 	}
 	dest := fmt.Sprintf("%s@%s:%s", job.Destination.User, job.Destination.Host, job.Destination.Path)
 
 	fmt.Println(ui.Info(fmt.Sprintf("Running job %q", job.Name)))
-	fmt.Println(ui.Label("  Source", src))
+	fmt.Println(ui.Label("  Source", sourcesLabel(job)))
 	fmt.Println(ui.Label("  Dest", dest))
 	fmt.Println()
 }
@@ -110,7 +167,7 @@ func printProgress(jobName string, evt backend.ProgressEvent) {
 	progressMu.Lock()
 	defer progressMu.Unlock()
 
-	if evt.Phase == "done" {
+	if evt.Phase == "done" || structuredOutput() {
 		return
 	}
 
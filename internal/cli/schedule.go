@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+	"github.com/klederson/keeper/internal/scheduler"
+	"github.com/klederson/keeper/internal/ui"
+)
+
+var scheduleCount int
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Show upcoming scheduled runs",
+	Long:  "List the next planned runs across all jobs and warn about runs that overlap on the same destination host.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		runs := scheduler.Plan(cfg.Jobs, time.Now(), scheduleCount)
+		if len(runs) == 0 {
+			fmt.Println(ui.Info("No scheduled jobs"))
+			return nil
+		}
+
+		store := reporter.NewStore()
+		durations := expectedDurations(store, cfg.Jobs)
+
+		fmt.Println(ui.Section("Upcoming Runs"))
+
+		columns := []ui.TableColumn{
+			{Title: "When", Width: 20},
+			{Title: "In", Width: 12},
+			{Title: "Job", Width: 18},
+			{Title: "Host", Width: 22},
+			{Title: "Jitter", Width: 10},
+			{Title: "Est. Duration", Width: 14},
+		}
+
+		rows := make([][]string, 0, len(runs))
+		for _, r := range runs {
+			jitter := ui.MutedStyle.Render("—")
+			if r.Jitter > 0 {
+				jitter = "+" + formatDuration(r.Jitter)
+			}
+			est := ui.MutedStyle.Render("—")
+			if d, ok := durations[r.Job]; ok {
+				est = formatDuration(d)
+			}
+			rows = append(rows, []string{
+				r.At.Local().Format("Mon Jan 02 15:04"),
+				formatTimeUntil(r.At),
+				r.Job,
+				r.Host,
+				jitter,
+				est,
+			})
+		}
+		fmt.Println(ui.Table(columns, rows))
+
+		overlaps := scheduler.FindOverlaps(runs, durations)
+		if len(overlaps) == 0 {
+			fmt.Println(ui.Success("No overlapping runs to the same host"))
+			return nil
+		}
+
+		fmt.Println(ui.Section("Overlaps"))
+		for _, o := range overlaps {
+			fmt.Println(ui.Warn(fmt.Sprintf("%s and %s both hit %s around %s",
+				o.A.Job, o.B.Job, o.Host, o.A.At.Local().Format("Jan 02 15:04"))))
+		}
+		fmt.Println()
+		fmt.Println(ui.Info("Set 'stagger' or 'jitter' on these jobs to spread them out"))
+		return nil
+	},
+}
+
+func init() {
+	scheduleCmd.Flags().IntVarP(&scheduleCount, "count", "n", 20, "Number of upcoming runs to show")
+}
+
+// expectedDurations averages the recent successful runs of each job.
+func expectedDurations(store *reporter.Store, jobs []config.Job) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	for _, job := range jobs {
+		var total time.Duration
+		var n int
+		for _, r := range store.GetJobRecords(job.Name, 10) {
+			if r.Success && !r.DryRun {
+				total += r.CompletedAt.Sub(r.StartedAt)
+				n++
+			}
+		}
+		if n > 0 {
+			durations[job.Name] = total / time.Duration(n)
+		}
+	}
+	return durations
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+	"github.com/klederson/keeper/internal/ui"
+)
+
+var statsSince string
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [job]",
+	Short: "Show per-job duration, throughput and failure statistics",
+	Long:  "Show p50, p95 and max duration, average throughput, change rate per run, last success and failure streaks for each job, or in detail for one job.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		since, err := parseSince(statsSince, now)
+		if err != nil {
+			return err
+		}
+		store := reporter.NewStore()
+
+		if len(args) == 1 {
+			job, _ := cfg.FindJob(args[0])
+			if job == nil {
+				return fmt.Errorf("job %q not found", args[0])
+			}
+			js := reporter.CalculateJobStats(store, job.Name, since)
+			fmt.Println(ui.Section(fmt.Sprintf("Stats for %s (since %s)", job.Name, since.Format("2006-01-02"))))
+			fmt.Println(ui.KeyValue(jobStatsPairs(js)))
+			return nil
+		}
+
+		fmt.Println(ui.Section(fmt.Sprintf("Job Stats (since %s)", since.Format("2006-01-02"))))
+
+		columns := []ui.TableColumn{
+			{Title: "Name", Width: 16},
+			{Title: "Runs", Width: 6},
+			{Title: "Success", Width: 8},
+			{Title: "p50", Width: 9},
+			{Title: "p95", Width: 9},
+			{Title: "Max", Width: 9},
+			{Title: "Throughput", Width: 12},
+			{Title: "Change", Width: 8},
+			{Title: "Last Success", Width: 13},
+			{Title: "Streak", Width: 8},
+		}
+
+		rows := make([][]string, 0, len(cfg.Jobs))
+		for _, job := range cfg.Jobs {
+			js := reporter.CalculateJobStats(store, job.Name, since)
+			if js.TotalRuns == 0 {
+				none := ui.MutedStyle.Render("—")
+				rows = append(rows, []string{job.Name, "0", none, none, none, none, none, none, lastSuccessLabel(js), none})
+				continue
+			}
+			rows = append(rows, []string{
+				job.Name,
+				fmt.Sprintf("%d", js.TotalRuns),
+				fmt.Sprintf("%.0f%%", js.SuccessRate),
+				formatDuration(js.DurationP50),
+				formatDuration(js.DurationP95),
+				formatDuration(js.DurationMax),
+				formatThroughput(js.Throughput),
+				fmt.Sprintf("%.1f%%", js.ChangeRate),
+				lastSuccessLabel(js),
+				streakLabel(js),
+			})
+		}
+
+		fmt.Println(ui.Table(columns, rows))
+		return nil
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsSince, "since", "30d", "Period to cover (e.g. 30d, 12h, 2024-01-31)")
+}
+
+// jobStatsPairs lays out one job's stats for ui.KeyValue.
+func jobStatsPairs(js reporter.JobStats) [][2]string {
+	longest := "none"
+	if s := js.LongestStreak; s.Runs > 0 {
+		longest = fmt.Sprintf("%d runs (%s – %s)", s.Runs, s.Start.Format("2006-01-02 15:04"), s.End.Format("2006-01-02 15:04"))
+	}
+	return [][2]string{
+		{"Runs", fmt.Sprintf("%d (%d ok, %d warnings, %d failed)", js.TotalRuns, js.SuccessCount, js.WarningCount, js.FailCount)},
+		{"Success rate", fmt.Sprintf("%.1f%%", js.SuccessRate)},
+		{"Duration p50", formatDuration(js.DurationP50)},
+		{"Duration p95", formatDuration(js.DurationP95)},
+		{"Duration max", formatDuration(js.DurationMax)},
+		{"Throughput", formatThroughput(js.Throughput)},
+		{"Change rate", fmt.Sprintf("%.1f%% of source per run", js.ChangeRate)},
+		{"Transferred", reporter.FormatBytes(js.TotalBytes)},
+		{"Last success", lastSuccessLabel(js)},
+		{"Failure streak", streakLabel(js)},
+		{"Longest streak", longest},
+	}
+}
+
+func lastSuccessLabel(js reporter.JobStats) string {
+	if js.LastSuccess == nil {
+		return ui.MutedStyle.Render("never")
+	}
+	return formatTimeAgo(js.LastSuccess.CompletedAt)
+}
+
+// streakLabel shows the failures since the last good run, in red when the
+// job is currently failing.
+func streakLabel(js reporter.JobStats) string {
+	if js.CurrentStreak == 0 {
+		return ui.AccentStyle.Render("0")
+	}
+	return ui.ErrorStyle.Render(fmt.Sprintf("%d", js.CurrentStreak))
+}
+
+func formatThroughput(bytesPerSec float64) string {
+	return reporter.FormatBytes(int64(bytesPerSec)) + "/s"
+}
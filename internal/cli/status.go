@@ -8,6 +8,7 @@ import (
 
 	"github.com/klederson/keeper/internal/config"
 	"github.com/klederson/keeper/internal/reporter"
+	"github.com/klederson/keeper/internal/scheduler"
 	"github.com/klederson/keeper/internal/ui"
 )
 
@@ -21,58 +22,98 @@ var statusCmd = &cobra.Command{
 		}
 
 		store := reporter.NewStore()
+		pauses := loadPauses()
 		allRecords := store.LoadAll()
 		stats30d := reporter.CalculateStats(allRecords, time.Now().AddDate(0, 0, -30))
 
+		if structuredOutput() {
+			now := time.Now()
+			out := statusOutput{
+				Period:       "30d",
+				TotalRuns:    stats30d.TotalRuns,
+				SuccessCount: stats30d.SuccessCount,
+				WarningCount: stats30d.WarningCount,
+				FailCount:    stats30d.FailCount,
+				SuccessRate:  stats30d.SuccessRate,
+				TotalBytes:   stats30d.TotalBytes,
+				AvgDuration:  stats30d.AvgDuration.Seconds(),
+				Jobs:         make([]jobStatusOutput, 0, len(cfg.Jobs)),
+			}
+			for i := range cfg.Jobs {
+				job := &cfg.Jobs[i]
+				out.Jobs = append(out.Jobs, jobStatusOutput{
+					jobOutput: newJobOutput(job, store, pauses, now),
+					RPO:       newRPOOutput(job, allRecords, now),
+				})
+			}
+			return printOutput(cmd, out)
+		}
+
 		fmt.Println(ui.Section("Keeper Status"))
 
 		// Overall stats
 		fmt.Println(ui.KeyValue([][2]string{
 			{"Success rate (30d)", fmt.Sprintf("%.1f%%", stats30d.SuccessRate)},
-			{"Total transferred", formatBytes(stats30d.TotalBytes)},
+			{"Total transferred", reporter.FormatBytes(stats30d.TotalBytes)},
 			{"Avg duration", formatDuration(stats30d.AvgDuration)},
 			{"Jobs run (30d)", fmt.Sprintf("%d", stats30d.TotalRuns)},
+			{"Warnings (30d)", fmt.Sprintf("%d", stats30d.WarningCount)},
+			{"Failures (30d)", fmt.Sprintf("%d", stats30d.FailCount)},
 		}))
 
 		fmt.Println(ui.Section("Jobs"))
 
 		columns := []ui.TableColumn{
 			{Title: "Name", Width: 16},
-			{Title: "Schedule", Width: 14},
+			{Title: "Schedule", Width: 18},
+			{Title: "Next Run", Width: 14},
 			{Title: "Last Run", Width: 12},
 			{Title: "Status", Width: 12},
 			{Title: "Duration", Width: 10},
 			{Title: "Transferred", Width: 14},
+			{Title: "Size", Width: 16},
+			{Title: "RPO", Width: 14},
 		}
 
 		rows := make([][]string, 0, len(cfg.Jobs))
+		now := time.Now()
 		for _, job := range cfg.Jobs {
 			records := store.GetJobRecords(job.Name, 1)
 
+			nextRun := ui.MutedStyle.Render("—")
+			if paused := pauseLabel(pauses, job.Name); paused != "" {
+				nextRun = paused
+			} else if next := scheduler.NextRun(&job, now); !next.IsZero() {
+				nextRun = formatTimeUntil(next)
+			} else if job.Trigger == config.TriggerWatch {
+				nextRun = ui.MutedStyle.Render("on change")
+			}
+
 			lastRun := ui.MutedStyle.Render("never")
 			status := ui.MutedStyle.Render("—")
 			duration := ui.MutedStyle.Render("—")
 			transferred := ui.MutedStyle.Render("—")
+			size := ui.MutedStyle.Render("—")
 
 			if len(records) > 0 {
 				r := records[0]
 				lastRun = formatTimeAgo(r.CompletedAt)
-				if r.Success {
-					status = ui.AccentStyle.Render("✓ success")
-				} else {
-					status = ui.ErrorStyle.Render("✗ failed")
-				}
+				status = runStatus(r)
 				duration = formatDuration(r.CompletedAt.Sub(r.StartedAt))
-				transferred = formatBytes(r.BytesTransferred)
+				transferred = reporter.FormatBytes(r.BytesTransferred)
+				size = sizeLabel(r)
 			}
 
 			rows = append(rows, []string{
 				job.Name,
-				job.Schedule,
+				scheduleLabel(&job),
+				nextRun,
 				lastRun,
 				status,
 				duration,
 				transferred,
+				size,
+				rpoLabel(&job, allRecords, now),
 			})
 		}
 
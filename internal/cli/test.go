@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -9,6 +8,7 @@ import (
 	"github.com/klederson/keeper/internal/backend"
 	"github.com/klederson/keeper/internal/backup"
 	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
 	"github.com/klederson/keeper/internal/ui"
 )
 
@@ -30,10 +30,13 @@ var testCmd = &cobra.Command{
 		}
 
 		printJobHeader(job)
-		fmt.Println(ui.Warn("Dry-run mode — no files will be transferred"))
-		fmt.Println()
+		if !structuredOutput() {
+			fmt.Println(ui.Warn("Dry-run mode — no files will be transferred"))
+			fmt.Println()
+		}
 
-		ctx := context.Background()
+		ctx, stop := interruptContext()
+		defer stop()
 		orch := backup.NewOrchestrator()
 		result, err := orch.Run(ctx, job, true, func(evt backend.ProgressEvent) {
 			printProgress(jobName, evt)
@@ -44,6 +47,12 @@ var testCmd = &cobra.Command{
 			return err
 		}
 
+		if structuredOutput() {
+			record := reporter.ResultToRecord(jobName, result, true)
+			record.Trigger = config.TriggerManual
+			return printOutput(cmd, runOutput{Runs: []config.RunRecord{record}})
+		}
+
 		backup.PrintResult(jobName, result, true)
 		return nil
 	},
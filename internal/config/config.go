@@ -2,9 +2,18 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,6 +22,69 @@ const (
 	DefaultConfigFile = "config.yaml"
 	DefaultDataDir    = ".local/share/keeper"
 	DefaultLogDir     = ".local/share/keeper/logs"
+
+	DefaultWatchDebounce    = 30 * time.Second
+	DefaultWatchMinInterval = 5 * time.Minute
+	DefaultShutdownTimeout  = 5 * time.Minute
+	DefaultSMTPPort         = 587
+	DefaultDigestPeriod     = 7 * 24 * time.Hour
+	DefaultPingTimeout      = 10 * time.Second
+	DefaultAnomalyDrop      = 50
+	DefaultAnomalySpike     = 100
+)
+
+// Run triggers recorded in RunRecord.Trigger. Schedule and watch are also
+// accepted by Job.Trigger. Catch-up marks a scheduled run started late
+// because its run window was closed; retry marks a run restarted after the
+// window closed on it mid-transfer.
+const (
+	TriggerManual   = "manual"
+	TriggerSchedule = "schedule"
+	TriggerWatch    = "watch"
+	TriggerCatchUp  = "catch-up"
+	TriggerRetry    = "retry"
+)
+
+// Notification target types accepted in NotifyTarget.Type.
+const (
+	NotifyWebhook = "webhook"
+	NotifyEmail   = "email"
+	NotifySlack   = "slack"
+	NotifyDiscord = "discord"
+	NotifyMatrix  = "matrix"
+	NotifyNtfy    = "ntfy"
+	NotifyGotify  = "gotify"
+)
+
+// Run outcomes, recorded in RunRecord.Outcome and used by notification
+// filters. A warning is a run that completed but hit errors the job's
+// warnings rules expect. Stale is raised by the daemon when a job's last
+// success is older than its max_age, and anomaly when a run's size falls
+// outside the job's baseline.
+const (
+	OutcomeSuccess = "success"
+	OutcomeWarning = "warning"
+	OutcomeFailure = "failure"
+	OutcomeStale   = "stale"
+	OutcomeAnomaly = "anomaly"
+)
+
+// Causes of rsync errors, recorded in RunError.Kind.
+const (
+	ErrPermissionDenied  = "permission_denied"
+	ErrNoSpace           = "no_space"
+	ErrHostKey           = "host_key"
+	ErrAuthFailed        = "auth_failed"
+	ErrConnectionRefused = "connection_refused"
+	ErrFileVanished      = "file_vanished"
+)
+
+// Reasons a run was stopped before rsync finished, recorded in RunRecord.AbortReason.
+const (
+	AbortWindowClosed = "window_closed"
+	AbortTimeout      = "timeout"
+	AbortStalled      = "stalled"
+	AbortInterrupted  = "interrupted"
 )
 
 func ConfigDir() string {
@@ -66,6 +138,7 @@ func Load() (*Config, error) {
 	if cfg.LogLevel == "" {
 		cfg.LogLevel = "info"
 	}
+	cfg.ApplyDefaults()
 
 	return &cfg, nil
 }
@@ -76,7 +149,7 @@ func Save(cfg *Config) error {
 		return fmt.Errorf("creating config dir: %w", err)
 	}
 
-	data, err := yaml.Marshal(cfg)
+	data, err := yaml.Marshal(cfg.Unresolved())
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
@@ -89,6 +162,153 @@ func Save(cfg *Config) error {
 	return nil
 }
 
+// JobPath is where a job backs up to when it inherits the default
+// destination path.
+func (d Defaults) JobPath(jobName string) string {
+	if d.Destination.Path == "" {
+		return ""
+	}
+	return path.Join(d.Destination.Path, jobName)
+}
+
+// destFields is a set of destination fields.
+type destFields uint8
+
+const (
+	destType destFields = 1 << iota
+	destHost
+	destUser
+	destPath
+	destSSHKey
+	destPort
+)
+
+// ApplyDefaults fills destination fields jobs leave unset from the
+// defaults. Load calls it; call it again after adding or editing a job.
+// Fields the job sets itself, even to the default's value, stay its own.
+func (c *Config) ApplyDefaults() {
+	d := c.Defaults.Destination
+	for i := range c.Jobs {
+		job := &c.Jobs[i]
+		dest := &job.Destination
+		inherit(&dest.Type, d.Type, destType, &job.inherited)
+		inherit(&dest.Host, d.Host, destHost, &job.inherited)
+		inherit(&dest.User, d.User, destUser, &job.inherited)
+		inherit(&dest.Path, c.Defaults.JobPath(job.Name), destPath, &job.inherited)
+		inherit(&dest.SSHKey, d.SSHKey, destSSHKey, &job.inherited)
+		inherit(&dest.Port, d.Port, destPort, &job.inherited)
+	}
+}
+
+// inherit fills an unset field from def and records it as inherited. A
+// field changed away from the default is no longer inherited.
+func inherit[T comparable](field *T, def T, flag destFields, inherited *destFields) {
+	var zero T
+	switch {
+	case *field == zero:
+		*field = def
+		if def != zero {
+			*inherited |= flag
+		} else {
+			*inherited &^= flag
+		}
+	case *field != def:
+		*inherited &^= flag
+	}
+}
+
+// Unresolved returns a copy of the config as Save writes it: destination
+// fields jobs inherit from the defaults are left unset again.
+func (c *Config) Unresolved() *Config {
+	out := *c
+	d := c.Defaults.Destination
+	out.Jobs = slices.Clone(c.Jobs)
+	for i := range out.Jobs {
+		job := &out.Jobs[i]
+		dest := &job.Destination
+		uninherit(&dest.Type, d.Type, destType, job.inherited)
+		uninherit(&dest.Host, d.Host, destHost, job.inherited)
+		uninherit(&dest.User, d.User, destUser, job.inherited)
+		uninherit(&dest.Path, c.Defaults.JobPath(job.Name), destPath, job.inherited)
+		uninherit(&dest.SSHKey, d.SSHKey, destSSHKey, job.inherited)
+		uninherit(&dest.Port, d.Port, destPort, job.inherited)
+		job.inherited = 0
+	}
+	return &out
+}
+
+// uninherit clears a field that was inherited and still holds the default.
+func uninherit[T comparable](field *T, def T, flag, inherited destFields) {
+	if inherited&flag != 0 && *field == def {
+		var zero T
+		*field = zero
+	}
+}
+
+func (c *Config) FindNotifyTarget(name string) *NotifyTarget {
+	for i := range c.Notifications.Targets {
+		if c.Notifications.Targets[i].Name == name {
+			return &c.Notifications.Targets[i]
+		}
+	}
+	return nil
+}
+
+// Outcomes returns the outcomes the target is notified about. Email targets
+// default to failures and staleness alerts; other targets to everything.
+func (t *NotifyTarget) Outcomes() []string {
+	if len(t.On) == 0 && t.Type == NotifyEmail {
+		return []string{OutcomeFailure, OutcomeStale}
+	}
+	return t.On
+}
+
+// Lookback returns how far back each digest looks.
+func (d *Digest) Lookback() time.Duration {
+	if d.Period > 0 {
+		return d.Period
+	}
+	return DefaultDigestPeriod
+}
+
+// StartURL, SuccessURL and FailURL return where each ping goes; empty means
+// that ping is not sent.
+func (p *Ping) StartURL() string {
+	return p.derive(p.Start, "/start")
+}
+
+func (p *Ping) SuccessURL() string {
+	return p.derive(p.Success, "")
+}
+
+func (p *Ping) FailURL() string {
+	return p.derive(p.Fail, "/fail")
+}
+
+func (p *Ping) derive(explicit, suffix string) string {
+	if explicit != "" || p.URL == "" {
+		return explicit
+	}
+	return strings.TrimRight(p.URL, "/") + suffix
+}
+
+// PingTimeout bounds each ping request.
+func (p *Ping) PingTimeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return DefaultPingTimeout
+}
+
+// Addr returns the server's host:port, defaulting to the submission port.
+func (s *SMTP) Addr() string {
+	port := s.Port
+	if port == 0 {
+		port = DefaultSMTPPort
+	}
+	return net.JoinHostPort(s.Host, strconv.Itoa(port))
+}
+
 func (c *Config) FindJob(name string) (*Job, int) {
 	for i := range c.Jobs {
 		if c.Jobs[i].Name == name {
@@ -116,10 +336,76 @@ func (c *Config) RemoveJob(name string) error {
 }
 
 func (c *Config) Validate() error {
+	if c.Metrics.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Metrics.Listen); err != nil {
+			return fmt.Errorf("metrics.listen: %w", err)
+		}
+	}
+
+	if c.History.MaxAge < 0 || c.History.MaxRecords < 0 {
+		return fmt.Errorf("history: max_age and max_records cannot be negative")
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range c.Notifications.Targets {
+		if t.Name == "" {
+			return fmt.Errorf("notification target name cannot be empty")
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("notification target %q defined twice", t.Name)
+		}
+		seen[t.Name] = true
+
+		switch t.Type {
+		case NotifyWebhook, NotifySlack, NotifyDiscord, NotifyNtfy:
+			if t.URL == "" {
+				return fmt.Errorf("notification target %q: url required", t.Name)
+			}
+		case NotifyMatrix:
+			if t.URL == "" || t.Room == "" || t.Token == "" {
+				return fmt.Errorf("notification target %q: url, room and token required", t.Name)
+			}
+		case NotifyGotify:
+			if t.URL == "" || t.Token == "" {
+				return fmt.Errorf("notification target %q: url and token required", t.Name)
+			}
+		case NotifyEmail:
+			if t.SMTP == nil || t.SMTP.Host == "" {
+				return fmt.Errorf("notification target %q: smtp.host required", t.Name)
+			}
+			if t.SMTP.From == "" || len(t.SMTP.To) == 0 {
+				return fmt.Errorf("notification target %q: smtp.from and smtp.to required", t.Name)
+			}
+		default:
+			return fmt.Errorf("notification target %q: unknown type %q", t.Name, t.Type)
+		}
+		for _, on := range t.On {
+			if !slices.Contains([]string{OutcomeSuccess, OutcomeWarning, OutcomeFailure, OutcomeStale, OutcomeAnomaly}, on) {
+				return fmt.Errorf("notification target %q: unknown outcome %q", t.Name, on)
+			}
+		}
+		if d := t.Digest; d != nil {
+			if t.Type != NotifyEmail {
+				return fmt.Errorf("notification target %q: digest is only supported for email targets", t.Name)
+			}
+			if _, err := cron.ParseStandard(d.Schedule); err != nil {
+				return fmt.Errorf("notification target %q: invalid digest schedule: %w", t.Name, err)
+			}
+			if d.Period < 0 {
+				return fmt.Errorf("notification target %q: digest period cannot be negative", t.Name)
+			}
+		}
+	}
+
+	jobNames := make(map[string]bool)
 	for _, job := range c.Jobs {
 		if job.Name == "" {
 			return fmt.Errorf("job name cannot be empty")
 		}
+		if jobNames[job.Name] {
+			return fmt.Errorf("job %q defined twice", job.Name)
+		}
+		jobNames[job.Name] = true
 		if len(job.Sources) == 0 {
 			return fmt.Errorf("job %q: at least one source required", job.Name)
 		}
@@ -137,10 +423,155 @@ func (c *Config) Validate() error {
 		if job.Destination.Path == "" {
 			return fmt.Errorf("job %q: destination path required", job.Name)
 		}
+		switch job.Trigger {
+		case "", TriggerSchedule, TriggerWatch:
+		default:
+			return fmt.Errorf("job %q: unknown trigger %q (use %q or %q)", job.Name, job.Trigger, TriggerSchedule, TriggerWatch)
+		}
+		if job.Watch.Debounce < 0 || job.Watch.MinInterval < 0 {
+			return fmt.Errorf("job %q: watch durations cannot be negative", job.Name)
+		}
+		if job.Schedule != "" {
+			if _, err := cron.ParseStandard(job.Schedule); err != nil {
+				return fmt.Errorf("job %q: invalid schedule %q: %w", job.Name, job.Schedule, err)
+			}
+		}
+		if job.Schedule != "" && job.Interval != 0 {
+			return fmt.Errorf("job %q: set either schedule or interval, not both", job.Name)
+		}
+		if job.Interval < 0 || (job.Interval > 0 && job.Interval < time.Minute) {
+			return fmt.Errorf("job %q: interval must be at least 1m", job.Name)
+		}
+		if job.Timeout < 0 || job.StallTimeout < 0 {
+			return fmt.Errorf("job %q: timeouts cannot be negative", job.Name)
+		}
+		if job.MaxAge < 0 {
+			return fmt.Errorf("job %q: max_age cannot be negative", job.Name)
+		}
+		if p := job.Ping; p != nil {
+			if p.URL == "" && p.Start == "" && p.Success == "" && p.Fail == "" {
+				return fmt.Errorf("job %q: ping needs a url", job.Name)
+			}
+			for _, u := range []string{p.URL, p.Start, p.Success, p.Fail} {
+				if u == "" {
+					continue
+				}
+				if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+					return fmt.Errorf("job %q: invalid ping url %q", job.Name, u)
+				}
+			}
+			if p.Timeout < 0 {
+				return fmt.Errorf("job %q: ping timeout cannot be negative", job.Name)
+			}
+		}
+		if w := job.Warnings; w != nil {
+			for _, p := range w.Patterns {
+				if _, err := regexp.Compile(p); err != nil {
+					return fmt.Errorf("job %q: invalid warning pattern %q: %w", job.Name, p, err)
+				}
+			}
+		}
+		if a := job.Anomaly; a != nil {
+			if a.Drop < 0 || a.Drop > 100 || a.Spike < 0 {
+				return fmt.Errorf("job %q: anomaly drop must be 0-100 and spike at least 0", job.Name)
+			}
+		}
+		if job.Jitter < 0 || job.Stagger < 0 {
+			return fmt.Errorf("job %q: jitter and stagger cannot be negative", job.Name)
+		}
+		if job.Timezone != "" {
+			if _, err := time.LoadLocation(job.Timezone); err != nil {
+				return fmt.Errorf("job %q: invalid timezone %q", job.Name, job.Timezone)
+			}
+		}
+		if w := job.Window; w != nil {
+			start, err1 := time.Parse("15:04", w.Start)
+			end, err2 := time.Parse("15:04", w.End)
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("job %q: window times must be HH:MM", job.Name)
+			}
+			if start.Equal(end) {
+				return fmt.Errorf("job %q: window start and end cannot be equal", job.Name)
+			}
+		}
 	}
 	return nil
 }
 
+// Enabled reports whether size anomalies are detected. A nil Anomaly uses
+// the defaults.
+func (a *Anomaly) Enabled() bool {
+	return a == nil || !a.Disabled
+}
+
+// Thresholds returns the drop and spike limits in percent.
+func (a *Anomaly) Thresholds() (drop, spike float64) {
+	drop, spike = DefaultAnomalyDrop, DefaultAnomalySpike
+	if a != nil && a.Drop > 0 {
+		drop = float64(a.Drop)
+	}
+	if a != nil && a.Spike > 0 {
+		spike = float64(a.Spike)
+	}
+	return drop, spike
+}
+
+// Status returns the run's outcome. Records written before outcomes were
+// recorded are success or failure according to Success.
+func (r RunRecord) Status() string {
+	if r.Outcome != "" {
+		return r.Outcome
+	}
+	if r.Success {
+		return OutcomeSuccess
+	}
+	return OutcomeFailure
+}
+
+// Enabled reports whether any retention limit is set.
+func (h History) Enabled() bool {
+	return h.MaxAge > 0 || h.MaxRecords > 0 || h.PruneRemovedJobs
+}
+
+// ShutdownGrace returns how long the daemon waits for running jobs on shutdown.
+func (c *Config) ShutdownGrace() time.Duration {
+	if c.ShutdownTimeout > 0 {
+		return c.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
+}
+
+// HasSchedule reports whether the daemon should run the job on a timer.
+func (j *Job) HasSchedule() bool {
+	return j.Schedule != "" || j.Interval > 0
+}
+
+// Location returns the job's time zone, falling back to the local zone.
+func (j *Job) Location() *time.Location {
+	if j.Timezone != "" {
+		if loc, err := time.LoadLocation(j.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// WatchDebounce returns how long changes must settle before a watch-triggered run.
+func (j *Job) WatchDebounce() time.Duration {
+	if j.Watch.Debounce > 0 {
+		return j.Watch.Debounce
+	}
+	return DefaultWatchDebounce
+}
+
+// WatchMinInterval returns the minimum time between two watch-triggered runs.
+func (j *Job) WatchMinInterval() time.Duration {
+	if j.Watch.MinInterval > 0 {
+		return j.Watch.MinInterval
+	}
+	return DefaultWatchMinInterval
+}
+
 func EnsureDataDir() error {
 	return os.MkdirAll(DataDir(), 0755)
 }
@@ -196,6 +196,79 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown trigger",
+			cfg: Config{
+				Jobs: []Job{{
+					Name:    "test",
+					Sources: []Source{{Path: "/tmp"}},
+					Trigger: "inotify",
+					Destination: Destination{
+						Type: "rsync",
+						Host: "example.com",
+						Path: "/backups",
+					},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "email target without recipients",
+			cfg: Config{
+				Notifications: Notifications{Targets: []NotifyTarget{{
+					Name: "mail",
+					Type: NotifyEmail,
+					SMTP: &SMTP{Host: "smtp.example.com", From: "keeper@example.com"},
+				}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "email digest with bad schedule",
+			cfg: Config{
+				Notifications: Notifications{Targets: []NotifyTarget{{
+					Name:   "mail",
+					Type:   NotifyEmail,
+					SMTP:   &SMTP{Host: "smtp.example.com", From: "keeper@example.com", To: []string{"ops@example.com"}},
+					Digest: &Digest{Schedule: "every monday"},
+				}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid warning pattern",
+			cfg: Config{
+				Jobs: []Job{{
+					Name:        "test",
+					Sources:     []Source{{Path: "/tmp"}},
+					Destination: Destination{Type: "rsync", Host: "nas", Path: "/backups"},
+					Warnings:    &Warnings{Patterns: []string{"vanished ("}},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid cron schedule",
+			cfg: Config{
+				Jobs: []Job{{
+					Name:        "test",
+					Sources:     []Source{{Path: "/tmp"}},
+					Destination: Destination{Type: "rsync", Host: "nas", Path: "/backups"},
+					Schedule:    "every night",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate job name",
+			cfg: Config{
+				Jobs: []Job{
+					{Name: "test", Sources: []Source{{Path: "/tmp"}}, Destination: Destination{Type: "rsync", Host: "nas", Path: "/a"}},
+					{Name: "test", Sources: []Source{{Path: "/srv"}}, Destination: Destination{Type: "rsync", Host: "nas", Path: "/b"}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing dest host",
 			cfg: Config{
@@ -241,3 +314,191 @@ func TestExpandPath(t *testing.T) {
 		}
 	}
 }
+
+func TestDefaultsInheritance(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := DefaultConfig()
+	cfg.Defaults.Destination = Destination{Type: "rsync", Host: "nas.local", User: "backup", Path: "/backups", SSHKey: "~/.ssh/nas"}
+	cfg.Jobs = []Job{
+		{Name: "docs", Sources: []Source{{Path: "/home/user/Documents"}}},
+		{Name: "media", Sources: []Source{{Path: "/home/user/Videos"}}, Destination: Destination{Host: "big.nas", Path: "/volume1/media", Port: 2222}},
+	}
+	if err := Save(&cfg); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := loaded.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	docs := loaded.Jobs[0].Destination
+	if docs != (Destination{Type: "rsync", Host: "nas.local", User: "backup", Path: "/backups/docs", SSHKey: "~/.ssh/nas"}) {
+		t.Errorf("docs destination = %+v", docs)
+	}
+	media := loaded.Jobs[1].Destination
+	if media.Host != "big.nas" || media.Path != "/volume1/media" || media.Port != 2222 || media.User != "backup" {
+		t.Errorf("media destination = %+v", media)
+	}
+
+	// Saving the loaded config must not copy the defaults into the jobs, so
+	// changing them later still applies.
+	if err := Save(loaded); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, _ := os.ReadFile(ConfigPath())
+	var raw Config
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if raw.Jobs[0].Destination != (Destination{}) {
+		t.Errorf("saved docs destination = %+v, want it inherited", raw.Jobs[0].Destination)
+	}
+	if raw.Jobs[1].Destination.Host != "big.nas" || raw.Jobs[1].Destination.User != "" {
+		t.Errorf("saved media destination = %+v", raw.Jobs[1].Destination)
+	}
+}
+
+func TestDefaultsKeepExplicitFields(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// A job that spells out the default host and user owns them: changing
+	// the defaults later must not move its backups.
+	data := `defaults:
+  destination:
+    type: rsync
+    host: nas.local
+    user: backup
+    path: /backups
+jobs:
+  - name: old
+    sources:
+      - path: /home/user/old
+    destination:
+      host: nas.local
+      user: backup
+      path: /backups/old
+    schedule: "@daily"
+`
+	if err := os.MkdirAll(ConfigDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ConfigPath(), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := Save(loaded); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	saved, _ := os.ReadFile(ConfigPath())
+	var raw Config
+	if err := yaml.Unmarshal(saved, &raw); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	want := Destination{Host: "nas.local", User: "backup", Path: "/backups/old"}
+	if raw.Jobs[0].Destination != want {
+		t.Errorf("saved destination = %+v, want %+v", raw.Jobs[0].Destination, want)
+	}
+
+	// Clearing a field hands it back to the defaults; setting an inherited
+	// one makes it the job's own.
+	loaded.Jobs[0].Destination.Host = ""
+	loaded.Jobs[0].Destination.Type = "local"
+	loaded.ApplyDefaults()
+	got := loaded.Unresolved().Jobs[0].Destination
+	want = Destination{Type: "local", User: "backup", Path: "/backups/old"}
+	if got != want {
+		t.Errorf("unresolved destination = %+v, want %+v", got, want)
+	}
+	if loaded.Jobs[0].Destination.Host != "nas.local" {
+		t.Errorf("resolved host = %q, want nas.local", loaded.Jobs[0].Destination.Host)
+	}
+}
+
+func TestSetKeepsInheritedDestination(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := DefaultConfig()
+	cfg.Defaults.Destination = Destination{Type: "rsync", Host: "nas.local", User: "backup", Path: "/backups", Port: 2222}
+	cfg.Jobs = []Job{{Name: "docs", Sources: []Source{{Path: "/home/user/Documents"}}, Schedule: "@daily"}}
+	if err := Save(&cfg); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := loaded.Jobs[0].Set("delete", "true"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	loaded.ApplyDefaults()
+	if err := Save(loaded); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, _ := os.ReadFile(ConfigPath())
+	var raw Config
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !raw.Jobs[0].Delete || raw.Jobs[0].Destination != (Destination{}) {
+		t.Errorf("saved job = %+v, want delete set and the destination still inherited", raw.Jobs[0])
+	}
+
+	reloaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := reloaded.Jobs[0].Destination; got.Host != "nas.local" || got.Path != "/backups/docs" || got.Port != 2222 {
+		t.Errorf("reloaded destination = %+v", got)
+	}
+}
+
+func TestSaveOmitsUnsetDestinationFields(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := DefaultConfig()
+	cfg.Defaults.Destination = Destination{Type: "rsync", Host: "nas.local", User: "backup", Path: "/backups"}
+	cfg.Jobs = []Job{
+		{Name: "docs", Sources: []Source{{Path: "/home/user/Documents"}}, Schedule: "@hourly"},
+		{Name: "media", Sources: []Source{{Path: "/home/user/Videos"}}, Schedule: "@daily", Destination: Destination{Host: "big.nas"}},
+	}
+	if err := Save(&cfg); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	loaded.Jobs[0].Schedule = "@daily"
+	loaded.ApplyDefaults()
+	if err := Save(loaded); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, _ := os.ReadFile(ConfigPath())
+	var raw struct {
+		Jobs []struct {
+			Destination map[string]any `yaml:"destination"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(raw.Jobs[0].Destination) != 0 {
+		t.Errorf("docs destination keys = %v, want none\n%s", raw.Jobs[0].Destination, data)
+	}
+	if d := raw.Jobs[1].Destination; len(d) != 1 || d["host"] != "big.nas" {
+		t.Errorf("media destination keys = %v, want only host\n%s", d, data)
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseJob decodes one job from YAML, as written under jobs: in the config.
+// Unknown fields are rejected so typos in provisioning files don't go
+// unnoticed.
+func ParseJob(data []byte) (*Job, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	var job Job
+	if err := dec.Decode(&job); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("empty job definition")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Clone returns a deep copy of the job, so editing it leaves j untouched.
+func (j Job) Clone() Job {
+	c := j
+	c.Sources = slices.Clone(j.Sources)
+	for i := range c.Sources {
+		c.Sources[i].Include = slices.Clone(c.Sources[i].Include)
+		c.Sources[i].Exclude = slices.Clone(c.Sources[i].Exclude)
+	}
+	if j.Window != nil {
+		w := *j.Window
+		c.Window = &w
+	}
+	if j.Ping != nil {
+		p := *j.Ping
+		c.Ping = &p
+	}
+	if j.Warnings != nil {
+		c.Warnings = &Warnings{
+			ExitCodes: slices.Clone(j.Warnings.ExitCodes),
+			Patterns:  slices.Clone(j.Warnings.Patterns),
+		}
+	}
+	if j.Anomaly != nil {
+		a := *j.Anomaly
+		c.Anomaly = &a
+	}
+	return c
+}
+
+// Set changes one field of the job, addressed by its YAML key with dots
+// for nesting and numbers for list items, e.g. "destination.host" or
+// "sources.0.exclude". The value is read as YAML, so "true", "4h" and
+// "[a, b]" work; an empty value resets the field.
+func (j *Job) Set(key, value string) error {
+	data, err := yaml.Marshal(j)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	var v any
+	if value != "" {
+		// Cron expressions such as "*/5 * * * *" aren't valid YAML; take
+		// anything that doesn't parse as a plain string.
+		if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+			v = value
+		}
+	}
+
+	doc, err = setPath(doc, strings.Split(key, "."), v, value == "")
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	data, err = yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	updated, err := ParseJob(data)
+	if err != nil {
+		// Line numbers refer to the re-encoded job, not to anything the
+		// user wrote, so report only the messages.
+		var te *yaml.TypeError
+		if errors.As(err, &te) {
+			msgs := make([]string, len(te.Errors))
+			for i, e := range te.Errors {
+				_, msgs[i], _ = strings.Cut(e, ": ")
+			}
+			return fmt.Errorf("%s: %s", key, strings.Join(msgs, "; "))
+		}
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	// Destination fields keep following the defaults unless the patch
+	// changed them; ApplyDefaults sorts that out.
+	updated.inherited = j.inherited
+	*j = *updated
+	return nil
+}
+
+// setPath sets, or with unset removes, the value at path inside a decoded
+// YAML document, creating sections as needed.
+func setPath(node any, path []string, v any, unset bool) (any, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	key, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case nil:
+		if unset {
+			return nil, nil
+		}
+		child, err := setPath(nil, rest, v, unset)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{key: child}, nil
+	case map[string]any:
+		if unset && len(rest) == 0 {
+			delete(n, key)
+			return n, nil
+		}
+		child, err := setPath(n[key], rest, v, unset)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = child
+		return n, nil
+	case []any:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i > len(n) {
+			return nil, fmt.Errorf("list index %q out of range (0-%d)", key, len(n))
+		}
+		if unset && len(rest) == 0 {
+			if i == len(n) {
+				return n, nil
+			}
+			return append(n[:i], n[i+1:]...), nil
+		}
+		if i == len(n) {
+			n = append(n, nil)
+		}
+		child, err := setPath(n[i], rest, v, unset)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = child
+		return n, nil
+	default:
+		return nil, fmt.Errorf("%q is not a section", key)
+	}
+}
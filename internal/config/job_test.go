@@ -0,0 +1,82 @@
+package config
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseJob(t *testing.T) {
+	job, err := ParseJob([]byte(`
+name: docs
+sources:
+  - path: /home/user/Documents
+destination:
+  type: rsync
+  host: nas.local
+  path: /backups/docs
+schedule: "@daily"
+timeout: 2h
+`))
+	if err != nil {
+		t.Fatalf("ParseJob: %v", err)
+	}
+	if job.Name != "docs" || job.Destination.Host != "nas.local" || job.Timeout != 2*time.Hour {
+		t.Errorf("parsed %+v", job)
+	}
+
+	if _, err := ParseJob([]byte("name: docs\nschedul: \"@daily\"\n")); err == nil {
+		t.Error("misspelt field accepted")
+	}
+	if _, err := ParseJob(nil); err == nil {
+		t.Error("empty input accepted")
+	}
+}
+
+func TestJobSet(t *testing.T) {
+	job := Job{
+		Name:        "docs",
+		Sources:     []Source{{Path: "/home/user/Documents", Exclude: []string{"*.tmp"}}},
+		Destination: Destination{Type: "rsync", Host: "nas.local", Path: "/backups/docs", Port: 22},
+		Schedule:    "0 2 * * *",
+		Timeout:     time.Hour,
+	}
+
+	sets := []struct{ key, value string }{
+		{"destination.host", "backup.example.com"},
+		{"schedule", "*/30 * * * *"},
+		{"delete", "true"},
+		{"bandwidth", "500k"},
+		{"sources.1.path", "/home/user/Pictures"},
+		{"sources.0.exclude", "[node_modules/, .git/]"},
+		{"window.start", "22:00"},
+		{"timeout", ""},
+	}
+	for _, s := range sets {
+		if err := job.Set(s.key, s.value); err != nil {
+			t.Fatalf("Set(%s=%s): %v", s.key, s.value, err)
+		}
+	}
+
+	if job.Destination.Host != "backup.example.com" || job.Destination.Port != 22 {
+		t.Errorf("destination = %+v", job.Destination)
+	}
+	if job.Schedule != "*/30 * * * *" || !job.Delete || job.Bandwidth != "500k" {
+		t.Errorf("schedule=%q delete=%v bandwidth=%q", job.Schedule, job.Delete, job.Bandwidth)
+	}
+	if len(job.Sources) != 2 || job.Sources[1].Path != "/home/user/Pictures" {
+		t.Errorf("sources = %+v", job.Sources)
+	}
+	if !slices.Equal(job.Sources[0].Exclude, []string{"node_modules/", ".git/"}) {
+		t.Errorf("excludes = %v", job.Sources[0].Exclude)
+	}
+	if job.Window == nil || job.Window.Start != "22:00" || job.Timeout != 0 {
+		t.Errorf("window=%+v timeout=%v", job.Window, job.Timeout)
+	}
+
+	for _, key := range []string{"destination.hots", "sources.5.path", "name.first"} {
+		if err := job.Set(key, "x"); err == nil {
+			t.Errorf("Set(%s) accepted", key)
+		}
+	}
+}
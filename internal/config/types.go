@@ -3,19 +3,148 @@ package config
 import "time"
 
 type Config struct {
-	LogDir   string `yaml:"log_dir" mapstructure:"log_dir"`
-	LogLevel string `yaml:"log_level" mapstructure:"log_level"`
-	Jobs     []Job  `yaml:"jobs" mapstructure:"jobs"`
+	LogDir          string        `yaml:"log_dir" mapstructure:"log_dir"`
+	LogLevel        string        `yaml:"log_level" mapstructure:"log_level"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout,omitempty" mapstructure:"shutdown_timeout"`
+	Metrics         Metrics       `yaml:"metrics,omitempty" mapstructure:"metrics"`
+	Notifications   Notifications `yaml:"notifications,omitempty" mapstructure:"notifications"`
+	History         History       `yaml:"history,omitempty" mapstructure:"history"`
+	Defaults        Defaults      `yaml:"defaults,omitempty" mapstructure:"defaults"`
+	Jobs            []Job         `yaml:"jobs" mapstructure:"jobs"`
+}
+
+// Defaults are inherited by jobs that leave them unset. The destination's
+// type, host, user, SSH key and port are copied as they are; Path is a base
+// directory, so a job without a destination path backs up to Path/<job name>.
+type Defaults struct {
+	Destination Destination `yaml:"destination,omitempty" mapstructure:"destination"`
+}
+
+type Notifications struct {
+	Targets []NotifyTarget `yaml:"targets,omitempty" mapstructure:"targets"`
+}
+
+// NotifyTarget is one place run notifications are delivered to. Jobs and
+// On filter which runs are sent; empty filters match everything.
+type NotifyTarget struct {
+	Name    string            `yaml:"name" mapstructure:"name"`
+	Type    string            `yaml:"type" mapstructure:"type"`
+	URL     string            `yaml:"url,omitempty" mapstructure:"url"`
+	Method  string            `yaml:"method,omitempty" mapstructure:"method"`
+	Headers map[string]string `yaml:"headers,omitempty" mapstructure:"headers"`
+	Body    string            `yaml:"body,omitempty" mapstructure:"body"`
+	Token   string            `yaml:"token,omitempty" mapstructure:"token"`
+	Room    string            `yaml:"room,omitempty" mapstructure:"room"`
+	Link    string            `yaml:"link,omitempty" mapstructure:"link"`
+	Jobs    []string          `yaml:"jobs,omitempty" mapstructure:"jobs"`
+	On      []string          `yaml:"on,omitempty" mapstructure:"on"`
+	Retries *int              `yaml:"retries,omitempty" mapstructure:"retries"`
+	SMTP    *SMTP             `yaml:"smtp,omitempty" mapstructure:"smtp"`
+	Digest  *Digest           `yaml:"digest,omitempty" mapstructure:"digest"`
+}
+
+// SMTP is the mail server and envelope used by email targets. Credentials
+// are sent with PLAIN auth, which requires StartTLS unless the server is
+// on localhost.
+type SMTP struct {
+	Host     string   `yaml:"host" mapstructure:"host"`
+	Port     int      `yaml:"port,omitempty" mapstructure:"port"`
+	Username string   `yaml:"username,omitempty" mapstructure:"username"`
+	Password string   `yaml:"password,omitempty" mapstructure:"password"`
+	StartTLS bool     `yaml:"starttls,omitempty" mapstructure:"starttls"`
+	From     string   `yaml:"from" mapstructure:"from"`
+	To       []string `yaml:"to" mapstructure:"to"`
+}
+
+// Digest schedules a summary email covering the Period before each run.
+type Digest struct {
+	Schedule string        `yaml:"schedule" mapstructure:"schedule"`
+	Period   time.Duration `yaml:"period,omitempty" mapstructure:"period"`
+}
+
+// History limits how much run history the daemon keeps. Zero values keep
+// everything. Rollup folds removed runs into per-day aggregates, and
+// PruneRemovedJobs deletes history of jobs no longer in the config.
+type History struct {
+	MaxAge           time.Duration `yaml:"max_age,omitempty" mapstructure:"max_age"`
+	MaxRecords       int           `yaml:"max_records,omitempty" mapstructure:"max_records"`
+	Rollup           bool          `yaml:"rollup,omitempty" mapstructure:"rollup"`
+	PruneRemovedJobs bool          `yaml:"prune_removed_jobs,omitempty" mapstructure:"prune_removed_jobs"`
+}
+
+// Metrics configures the daemon's Prometheus endpoint. It is disabled when
+// Listen is empty.
+type Metrics struct {
+	Listen string `yaml:"listen,omitempty" mapstructure:"listen"`
 }
 
 type Job struct {
-	Name        string      `yaml:"name" mapstructure:"name"`
-	Sources     []Source    `yaml:"sources" mapstructure:"sources"`
-	Destination Destination `yaml:"destination" mapstructure:"destination"`
-	Schedule    string      `yaml:"schedule" mapstructure:"schedule"`
-	Bandwidth   string      `yaml:"bandwidth" mapstructure:"bandwidth"`
-	Delete      bool        `yaml:"delete" mapstructure:"delete"`
-	Compress    bool        `yaml:"compress" mapstructure:"compress"`
+	Name         string        `yaml:"name" mapstructure:"name"`
+	Sources      []Source      `yaml:"sources" mapstructure:"sources"`
+	Destination  Destination   `yaml:"destination" mapstructure:"destination"`
+	Schedule     string        `yaml:"schedule" mapstructure:"schedule"`
+	Interval     time.Duration `yaml:"interval,omitempty" mapstructure:"interval"`
+	Timezone     string        `yaml:"timezone,omitempty" mapstructure:"timezone"`
+	Window       *Window       `yaml:"window,omitempty" mapstructure:"window"`
+	Jitter       time.Duration `yaml:"jitter,omitempty" mapstructure:"jitter"`
+	Stagger      time.Duration `yaml:"stagger,omitempty" mapstructure:"stagger"`
+	Bandwidth    string        `yaml:"bandwidth" mapstructure:"bandwidth"`
+	Delete       bool          `yaml:"delete" mapstructure:"delete"`
+	Compress     bool          `yaml:"compress" mapstructure:"compress"`
+	Timeout      time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout"`
+	StallTimeout time.Duration `yaml:"stall_timeout,omitempty" mapstructure:"stall_timeout"`
+	MaxAge       time.Duration `yaml:"max_age,omitempty" mapstructure:"max_age"`
+	Ping         *Ping         `yaml:"ping,omitempty" mapstructure:"ping"`
+	Warnings     *Warnings     `yaml:"warnings,omitempty" mapstructure:"warnings"`
+	Anomaly      *Anomaly      `yaml:"anomaly,omitempty" mapstructure:"anomaly"`
+	Trigger      string        `yaml:"trigger,omitempty" mapstructure:"trigger"`
+	Watch        Watch         `yaml:"watch,omitempty" mapstructure:"watch"`
+
+	// inherited marks the destination fields ApplyDefaults filled in, so
+	// Save leaves them out and the job keeps following the defaults.
+	inherited destFields
+}
+
+// Window restricts runs to a daily time range, e.g. 22:00-06:00. Times are
+// in the job's time zone; a window whose end is before its start spans midnight.
+type Window struct {
+	Start string `yaml:"start" mapstructure:"start"`
+	End   string `yaml:"end" mapstructure:"end"`
+}
+
+// Ping sends healthchecks.io-style pings around each run: URL/start when it
+// begins, URL on success and URL/fail on failure. Start, Success and Fail
+// override the derived URLs; set only those to use a different monitor.
+type Ping struct {
+	URL     string        `yaml:"url,omitempty" mapstructure:"url"`
+	Start   string        `yaml:"start,omitempty" mapstructure:"start"`
+	Success string        `yaml:"success,omitempty" mapstructure:"success"`
+	Fail    string        `yaml:"fail,omitempty" mapstructure:"fail"`
+	Timeout time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout"`
+}
+
+// Warnings downgrades expected rsync errors, such as exit code 24 (files
+// vanished during transfer), from failure to warning. A source whose rsync
+// exits with one of ExitCodes, or whose every stderr line matches one of
+// Patterns, finishes with a warning.
+type Warnings struct {
+	ExitCodes []int    `yaml:"exit_codes,omitempty" mapstructure:"exit_codes"`
+	Patterns  []string `yaml:"patterns,omitempty" mapstructure:"patterns"`
+}
+
+// Anomaly tunes detection of unusual backup sizes. A run is anomalous when
+// its total bytes or files fall by more than Drop percent, or rise by more
+// than Spike percent, against the median of the job's recent runs.
+type Anomaly struct {
+	Disabled bool `yaml:"disabled,omitempty" mapstructure:"disabled"`
+	Drop     int  `yaml:"drop,omitempty" mapstructure:"drop"`
+	Spike    int  `yaml:"spike,omitempty" mapstructure:"spike"`
+}
+
+// Watch tunes filesystem-watch triggered runs (trigger: watch).
+type Watch struct {
+	Debounce    time.Duration `yaml:"debounce,omitempty" mapstructure:"debounce"`
+	MinInterval time.Duration `yaml:"min_interval,omitempty" mapstructure:"min_interval"`
 }
 
 type Source struct {
@@ -25,23 +154,78 @@ type Source struct {
 }
 
 type Destination struct {
-	Type   string `yaml:"type" mapstructure:"type"`
-	Host   string `yaml:"host" mapstructure:"host"`
-	User   string `yaml:"user" mapstructure:"user"`
-	Path   string `yaml:"path" mapstructure:"path"`
-	SSHKey string `yaml:"ssh_key" mapstructure:"ssh_key"`
-	Port   int    `yaml:"port" mapstructure:"port"`
+	Type   string `yaml:"type,omitempty" mapstructure:"type"`
+	Host   string `yaml:"host,omitempty" mapstructure:"host"`
+	User   string `yaml:"user,omitempty" mapstructure:"user"`
+	Path   string `yaml:"path,omitempty" mapstructure:"path"`
+	SSHKey string `yaml:"ssh_key,omitempty" mapstructure:"ssh_key"`
+	Port   int    `yaml:"port,omitempty" mapstructure:"port"`
 }
 
+// RunRecord is one run as stored in history. Fields added after the first
+// release are optional so older records still load.
 type RunRecord struct {
-	JobName          string    `json:"job_name"`
-	StartedAt        time.Time `json:"started_at"`
-	CompletedAt      time.Time `json:"completed_at"`
-	Success          bool      `json:"success"`
-	FilesTotal       int       `json:"files_total"`
-	FilesTransferred int       `json:"files_transferred"`
-	BytesTotal       int64     `json:"bytes_total"`
-	BytesTransferred int64     `json:"bytes_transferred"`
-	Errors           []string  `json:"errors,omitempty"`
-	DryRun           bool      `json:"dry_run"`
+	RunID            string        `json:"run_id,omitempty"`
+	JobName          string        `json:"job_name"`
+	StartedAt        time.Time     `json:"started_at"`
+	CompletedAt      time.Time     `json:"completed_at"`
+	Success          bool          `json:"success"`
+	FilesTotal       int           `json:"files_total"`
+	FilesTransferred int           `json:"files_transferred"`
+	BytesTotal       int64         `json:"bytes_total"`
+	BytesTransferred int64         `json:"bytes_transferred"`
+	Errors           []string      `json:"errors,omitempty"`
+	Warnings         []string      `json:"warnings,omitempty"`
+	TypedErrors      []RunError    `json:"typed_errors,omitempty"`
+	Anomalies        []SizeAnomaly `json:"anomalies,omitempty"`
+	Outcome          string        `json:"outcome,omitempty"`
+	DryRun           bool          `json:"dry_run"`
+	Trigger          string        `json:"trigger,omitempty"`
+	AbortReason      string        `json:"abort_reason,omitempty"`
+	Hostname         string        `json:"hostname,omitempty"`
+	KeeperVersion    string        `json:"keeper_version,omitempty"`
+	RsyncVersion     string        `json:"rsync_version,omitempty"`
+	// ExitCode is the first non-zero rsync exit code among the sources.
+	ExitCode int            `json:"exit_code,omitempty"`
+	Sources  []SourceRecord `json:"sources,omitempty"`
+}
+
+// SourceRecord is the part of a run that transferred one source.
+type SourceRecord struct {
+	Path             string     `json:"path"`
+	FilesTotal       int        `json:"files_total"`
+	FilesTransferred int        `json:"files_transferred"`
+	BytesTotal       int64      `json:"bytes_total"`
+	BytesTransferred int64      `json:"bytes_transferred"`
+	Errors           []string   `json:"errors,omitempty"`
+	Warnings         []string   `json:"warnings,omitempty"`
+	TypedErrors      []RunError `json:"typed_errors,omitempty"`
+	ExitCode         int        `json:"exit_code,omitempty"`
+}
+
+// SizeAnomaly records a run whose size fell outside the job's baseline.
+// Metric is "bytes_total" or "files_total"; Change is the difference from
+// the baseline in percent.
+type SizeAnomaly struct {
+	Metric   string  `json:"metric"`
+	Value    int64   `json:"value"`
+	Baseline int64   `json:"baseline"`
+	Change   float64 `json:"change"`
+}
+
+// RunError groups a run's rsync messages that share a recognised cause.
+// Paths holds the first few files affected, when the messages name them;
+// PathCount is how many distinct files they named in all.
+type RunError struct {
+	Kind      string   `json:"kind"`
+	Count     int      `json:"count"`
+	Paths     []string `json:"paths,omitempty"`
+	PathCount int      `json:"path_count,omitempty"`
+	Message   string   `json:"message"`
+}
+
+// Success reports whether the source transferred without errors. Warnings
+// don't count against it.
+func (s SourceRecord) Success() bool {
+	return len(s.Errors) == 0
 }
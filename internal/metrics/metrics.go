@@ -0,0 +1,269 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klederson/keeper/internal/backend"
+	"github.com/klederson/keeper/internal/backup"
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+)
+
+// outcomes are the label values of keeper_job_runs_total.
+var outcomes = []string{config.OutcomeSuccess, config.OutcomeWarning, config.OutcomeFailure}
+
+// recentRuns is how many of a job's newest records a scrape reads to pick up
+// runs the daemon didn't make itself, such as 'keeper run' from the CLI.
+const recentRuns = 20
+
+type jobMetrics struct {
+	lastSuccess  float64
+	lastDuration float64
+	lastBytes    int64
+	lastFiles    int
+	bytesTotal   int64
+	filesTotal   int64
+	runs         map[string]int64
+	running      bool
+
+	// lastRun is when the newest counted run completed; history older than
+	// that is already in the totals.
+	lastRun time.Time
+}
+
+// Collector keeps per-job metrics, seeded from run history and updated from
+// orchestrator events and, on each scrape, from runs recorded since. It
+// serves them in the Prometheus text format.
+type Collector struct {
+	mu    sync.Mutex
+	jobs  map[string]*jobMetrics
+	store *reporter.Store
+
+	// QueueDepth reports runs triggered but not started yet, if set.
+	QueueDepth func() int
+}
+
+func New() *Collector {
+	return &Collector{
+		jobs: make(map[string]*jobMetrics),
+	}
+}
+
+func (c *Collector) job(name string) *jobMetrics {
+	m, ok := c.jobs[name]
+	if !ok {
+		m = &jobMetrics{runs: make(map[string]int64)}
+		c.jobs[name] = m
+	}
+	return m
+}
+
+// Seed loads totals and last-run values for the given jobs from history and
+// keeps store to catch up on later scrapes. Runs already counted are
+// skipped, so it is safe to call on reload.
+func (c *Collector) Seed(store *reporter.Store, jobs []config.Job) {
+	c.mu.Lock()
+	c.store = store
+	c.mu.Unlock()
+
+	for _, job := range jobs {
+		c.catchUp(store, job.Name, 0)
+	}
+}
+
+// catchUp counts the job's recorded runs that completed after the newest
+// one it has seen, reading at most limit of the newest records, or all of
+// them for 0.
+func (c *Collector) catchUp(store *reporter.Store, jobName string, limit int) {
+	records := store.GetJobRecords(jobName, limit)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.job(jobName)
+	// Records are newest first; replay oldest first so "last" wins.
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].DryRun || !records[i].CompletedAt.After(m.lastRun) {
+			continue
+		}
+		m.observe(recordRun(records[i]))
+	}
+}
+
+// HandleEvent updates metrics from an orchestrator event.
+func (c *Collector) HandleEvent(evt backup.Event) {
+	if evt.DryRun {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.job(evt.Job)
+	switch evt.Type {
+	case backup.EventStarted:
+		m.running = true
+	case backup.EventFinished:
+		m.running = false
+		if evt.Result == nil {
+			m.runs[config.OutcomeFailure]++
+			return
+		}
+		m.observe(resultRun(evt.Result))
+	}
+}
+
+// run is the subset of a finished run the collector cares about.
+type run struct {
+	outcome   string
+	completed time.Time
+	duration  float64
+	bytes     int64
+	files     int
+}
+
+func recordRun(r config.RunRecord) run {
+	return run{
+		outcome:   r.Status(),
+		completed: r.CompletedAt,
+		duration:  r.CompletedAt.Sub(r.StartedAt).Seconds(),
+		bytes:     r.BytesTransferred,
+		files:     r.FilesTransferred,
+	}
+}
+
+func resultRun(r *backend.Result) run {
+	return run{
+		outcome:   r.Outcome(),
+		completed: r.CompletedAt,
+		duration:  r.CompletedAt.Sub(r.StartedAt).Seconds(),
+		bytes:     r.BytesTransferred,
+		files:     r.FilesTransferred,
+	}
+}
+
+func (m *jobMetrics) observe(r run) {
+	m.lastDuration = r.duration
+	m.lastBytes = r.bytes
+	m.lastFiles = r.files
+	m.bytesTotal += r.bytes
+	m.filesTotal += int64(r.files)
+
+	m.runs[r.outcome]++
+	if r.outcome != config.OutcomeFailure {
+		m.lastSuccess = float64(r.completed.Unix())
+	}
+	if r.completed.After(m.lastRun) {
+		m.lastRun = r.completed
+	}
+}
+
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo renders all metrics in the Prometheus text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	store := c.store
+	tracked := make([]string, 0, len(c.jobs))
+	for name := range c.jobs {
+		tracked = append(tracked, name)
+	}
+	c.mu.Unlock()
+	if store != nil {
+		for _, name := range tracked {
+			c.catchUp(store, name, recentRuns)
+		}
+	}
+
+	c.mu.Lock()
+	names := make([]string, 0, len(c.jobs))
+	for name := range c.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	gauge := func(name, help string, value func(*jobMetrics) string) {
+		header(&b, name, "gauge", help)
+		for _, job := range names {
+			fmt.Fprintf(&b, "%s{job=%s} %s\n", name, label(job), value(c.jobs[job]))
+		}
+	}
+
+	gauge("keeper_job_last_success_timestamp_seconds", "Unix time the job last completed successfully.",
+		func(m *jobMetrics) string { return formatFloat(m.lastSuccess) })
+	gauge("keeper_job_last_run_duration_seconds", "Duration of the job's most recent run.",
+		func(m *jobMetrics) string { return formatFloat(m.lastDuration) })
+	gauge("keeper_job_last_run_bytes_transferred", "Bytes transferred by the job's most recent run.",
+		func(m *jobMetrics) string { return fmt.Sprint(m.lastBytes) })
+	gauge("keeper_job_last_run_files_transferred", "Files transferred by the job's most recent run.",
+		func(m *jobMetrics) string { return fmt.Sprint(m.lastFiles) })
+	gauge("keeper_job_running", "Whether the job is currently running.",
+		func(m *jobMetrics) string { return boolValue(m.running) })
+
+	header(&b, "keeper_job_bytes_transferred_total", "counter", "Bytes transferred across all runs of the job.")
+	for _, job := range names {
+		fmt.Fprintf(&b, "keeper_job_bytes_transferred_total{job=%s} %d\n", label(job), c.jobs[job].bytesTotal)
+	}
+	header(&b, "keeper_job_files_transferred_total", "counter", "Files transferred across all runs of the job.")
+	for _, job := range names {
+		fmt.Fprintf(&b, "keeper_job_files_transferred_total{job=%s} %d\n", label(job), c.jobs[job].filesTotal)
+	}
+
+	header(&b, "keeper_job_runs_total", "counter", "Completed runs of the job by outcome.")
+	for _, job := range names {
+		for _, outcome := range outcomes {
+			fmt.Fprintf(&b, "keeper_job_runs_total{job=%s,outcome=%s} %d\n", label(job), label(outcome), c.jobs[job].runs[outcome])
+		}
+	}
+
+	running := 0
+	for _, m := range c.jobs {
+		if m.running {
+			running++
+		}
+	}
+	c.mu.Unlock()
+
+	header(&b, "keeper_jobs_running", "gauge", "Number of jobs currently running.")
+	fmt.Fprintf(&b, "keeper_jobs_running %d\n", running)
+
+	if c.QueueDepth != nil {
+		header(&b, "keeper_queue_depth", "gauge", "Runs triggered but not started yet.")
+		fmt.Fprintf(&b, "keeper_queue_depth %d\n", c.QueueDepth())
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func header(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label renders a quoted label value as the exposition format expects.
+func label(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
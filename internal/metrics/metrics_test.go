@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/backend"
+	"github.com/klederson/keeper/internal/backup"
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+)
+
+func TestCollectorEvents(t *testing.T) {
+	c := New()
+	c.QueueDepth = func() int { return 2 }
+
+	start := time.Unix(1_700_000_000, 0)
+	c.HandleEvent(backup.Event{Type: backup.EventStarted, Job: "web"})
+	c.HandleEvent(backup.Event{Type: backup.EventStarted, Job: "db"})
+	c.HandleEvent(backup.Event{Type: backup.EventFinished, Job: "web", Result: &backend.Result{
+		StartedAt:        start,
+		CompletedAt:      start.Add(90 * time.Second),
+		Success:          true,
+		BytesTransferred: 2048,
+		FilesTransferred: 3,
+	}})
+	c.HandleEvent(backup.Event{Type: backup.EventFinished, Job: "web", Result: &backend.Result{
+		StartedAt:   start.Add(time.Hour),
+		CompletedAt: start.Add(time.Hour + time.Second),
+		Success:     false,
+	}})
+	// Dry runs are not production runs.
+	c.HandleEvent(backup.Event{Type: backup.EventFinished, Job: "web", DryRun: true, Result: &backend.Result{Success: true}})
+
+	var b strings.Builder
+	c.WriteTo(&b)
+	out := b.String()
+
+	want := []string{
+		`keeper_job_last_success_timestamp_seconds{job="web"} 1700000090`,
+		`keeper_job_last_run_duration_seconds{job="web"} 1`,
+		`keeper_job_bytes_transferred_total{job="web"} 2048`,
+		`keeper_job_files_transferred_total{job="web"} 3`,
+		`keeper_job_runs_total{job="web",outcome="success"} 1`,
+		`keeper_job_runs_total{job="web",outcome="failure"} 1`,
+		`keeper_job_running{job="db"} 1`,
+		`keeper_job_running{job="web"} 0`,
+		`keeper_jobs_running 1`,
+		`keeper_queue_depth 2`,
+		`# TYPE keeper_job_runs_total counter`,
+	}
+	for _, line := range want {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %q in output:\n%s", line, out)
+		}
+	}
+}
+
+func TestCollectorCatchesUpOnHistory(t *testing.T) {
+	store := reporter.NewStoreAt(t.TempDir())
+	start := time.Unix(1_700_000_000, 0)
+	run := func(at time.Duration, bytes int64) config.RunRecord {
+		return config.RunRecord{JobName: "web", StartedAt: start.Add(at), CompletedAt: start.Add(at + time.Minute), Success: true, BytesTransferred: bytes}
+	}
+	store.Append(run(0, 100))
+
+	c := New()
+	c.Seed(store, []config.Job{{Name: "web"}})
+
+	// The daemon's own run arrives as an event and is then recorded; a
+	// later run from the CLI only reaches the store.
+	daemon := run(time.Hour, 200)
+	c.HandleEvent(backup.Event{Type: backup.EventFinished, Job: "web", Result: &backend.Result{
+		StartedAt: daemon.StartedAt, CompletedAt: daemon.CompletedAt, Success: true, BytesTransferred: 200,
+	}})
+	store.Append(daemon)
+	store.Append(run(2*time.Hour, 400))
+
+	var b strings.Builder
+	c.WriteTo(&b)
+	c.Seed(store, []config.Job{{Name: "web"}})
+	b.Reset()
+	c.WriteTo(&b)
+	out := b.String()
+
+	want := []string{
+		`keeper_job_last_run_bytes_transferred{job="web"} 400`,
+		`keeper_job_bytes_transferred_total{job="web"} 700`,
+		`keeper_job_runs_total{job="web",outcome="success"} 3`,
+		`keeper_job_last_success_timestamp_seconds{job="web"} 1700007260`,
+	}
+	for _, line := range want {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %q in output:\n%s", line, out)
+		}
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	if got := label(`a"b\c`); got != `"a\"b\\c"` {
+		t.Errorf("label() = %s", got)
+	}
+}
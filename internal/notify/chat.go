@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+)
+
+// chat holds what every chat-service notifier shares: where to post, extra
+// headers and credentials, and the optional link template.
+type chat struct {
+	url     string
+	token   string
+	headers map[string]string
+	link    *template.Template
+	client  *http.Client
+}
+
+func newChat(target config.NotifyTarget) (chat, error) {
+	c := chat{
+		url:     strings.TrimRight(target.URL, "/"),
+		token:   target.Token,
+		headers: target.Headers,
+		client:  &http.Client{},
+	}
+	if target.Link != "" {
+		tmpl, err := template.New(target.Name).Funcs(templateFuncs).Parse(target.Link)
+		if err != nil {
+			return c, fmt.Errorf("parsing link template: %w", err)
+		}
+		c.link = tmpl
+	}
+	return c, nil
+}
+
+// postJSON posts v as JSON to url with the target's headers plus extra.
+func (c chat) postJSON(ctx context.Context, method, url string, extra map[string]string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+	return post(ctx, c.client, method, url, c.withHeaders(extra), "application/json", body)
+}
+
+func (c chat) withHeaders(extra map[string]string) map[string]string {
+	headers := make(map[string]string, len(c.headers)+len(extra))
+	for k, v := range extra {
+		headers[k] = v
+	}
+	for k, v := range c.headers {
+		headers[k] = v
+	}
+	return headers
+}
+
+// summary is the content every chat message carries, ready to format.
+type summary struct {
+	Failed   bool
+	Icon     string
+	Title    string
+	Job      string
+	Duration string
+	Bytes    string
+	Error    string
+	Link     string
+}
+
+func (c chat) summarise(evt Event) (summary, error) {
+	s := summary{
+		Failed: evt.Outcome != config.OutcomeSuccess,
+		Icon:   "✅",
+		Title:  fmt.Sprintf("%s on %s", evt.Message, evt.Hostname),
+		Job:    evt.Job,
+		Error:  evt.FirstError(),
+	}
+	switch evt.Outcome {
+	case config.OutcomeWarning:
+		s.Icon = "⚠️"
+	case config.OutcomeFailure:
+		s.Icon = "❌"
+	case config.OutcomeStale:
+		s.Icon = "⏰"
+	case config.OutcomeAnomaly:
+		s.Icon = "📦"
+	}
+	// A stale alert's record is the last success, not a run to report on.
+	if evt.Record != nil && evt.Kind != KindStale {
+		s.Duration = evt.Duration().String()
+		s.Bytes = reporter.FormatBytes(evt.Record.BytesTransferred)
+	}
+	if c.link != nil {
+		var b bytes.Buffer
+		if err := c.link.Execute(&b, evt); err != nil {
+			return s, fmt.Errorf("rendering link: %w", err)
+		}
+		s.Link = strings.TrimSpace(b.String())
+	}
+	return s, nil
+}
+
+// fields are the label/value pairs shown under the title.
+func (s summary) fields() [][2]string {
+	f := [][2]string{{"Job", s.Job}}
+	if s.Duration != "" {
+		f = append(f, [2]string{"Duration", s.Duration}, [2]string{"Transferred", s.Bytes})
+	}
+	if s.Error != "" {
+		f = append(f, [2]string{"Error", s.Error})
+	}
+	return f
+}
+
+// text renders the summary as plain text, one field per line.
+func (s summary) text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s", s.Icon, s.Title)
+	for _, f := range s.fields() {
+		fmt.Fprintf(&b, "\n%s: %s", f[0], f[1])
+	}
+	if s.Link != "" {
+		fmt.Fprintf(&b, "\n%s", s.Link)
+	}
+	return b.String()
+}
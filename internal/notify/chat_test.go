@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+const testLink = "https://dash.example.com/jobs/{{ .Job }}"
+
+// request is what a stand-in server saw.
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// standIn serves one request and records it.
+func standIn(t *testing.T) (*httptest.Server, *request) {
+	t.Helper()
+	got := &request{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.method = r.Method
+		got.path = r.URL.EscapedPath()
+		got.header = r.Header
+		got.body, _ = io.ReadAll(r.Body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+func notifyVia(t *testing.T, target config.NotifyTarget) {
+	t.Helper()
+	n, err := New(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), RunEvent(failedRecord())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func decode(t *testing.T, data []byte, v any) {
+	t.Helper()
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	srv, got := standIn(t)
+	notifyVia(t, config.NotifyTarget{Name: "slack", Type: config.NotifySlack, URL: srv.URL, Link: testLink})
+
+	var msg slackMessage
+	decode(t, got.body, &msg)
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("attachments = %+v", msg.Attachments)
+	}
+	att := msg.Attachments[0]
+	if att.Color != "danger" || !strings.HasPrefix(att.Title, "❌ ") || att.TitleLink != "https://dash.example.com/jobs/web" {
+		t.Errorf("attachment = %+v", att)
+	}
+	wantFields := map[string]string{"Job": "web", "Duration": "1m30s", "Transferred": "2.0 KB", "Error": "disk full"}
+	for _, f := range att.Fields {
+		if wantFields[f.Title] != f.Value {
+			t.Errorf("field %s = %q, want %q", f.Title, f.Value, wantFields[f.Title])
+		}
+		delete(wantFields, f.Title)
+	}
+	if len(wantFields) > 0 {
+		t.Errorf("missing fields %v", wantFields)
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	srv, got := standIn(t)
+	notifyVia(t, config.NotifyTarget{Name: "discord", Type: config.NotifyDiscord, URL: srv.URL, Link: testLink})
+
+	var msg discordMessage
+	decode(t, got.body, &msg)
+	if len(msg.Embeds) != 1 {
+		t.Fatalf("embeds = %+v", msg.Embeds)
+	}
+	e := msg.Embeds[0]
+	if e.Color != discordRed || e.URL != "https://dash.example.com/jobs/web" || !strings.HasPrefix(e.Title, "❌ ") {
+		t.Errorf("embed = %+v", e)
+	}
+	if len(e.Fields) != 4 || e.Fields[3].Name != "Error" || e.Fields[3].Value != "disk full" || e.Fields[3].Inline {
+		t.Errorf("fields = %+v", e.Fields)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	srv, got := standIn(t)
+	notifyVia(t, config.NotifyTarget{
+		Name: "matrix", Type: config.NotifyMatrix,
+		URL: srv.URL + "/", Room: "!ops:example.org", Token: "tok", Link: testLink,
+	})
+
+	if got.method != http.MethodPut {
+		t.Errorf("method = %s", got.method)
+	}
+	if !strings.HasPrefix(got.path, "/_matrix/client/v3/rooms/%21ops:example.org/send/m.room.message/keeper-") {
+		t.Errorf("path = %s", got.path)
+	}
+	if got.header.Get("Authorization") != "Bearer tok" {
+		t.Errorf("Authorization = %q", got.header.Get("Authorization"))
+	}
+
+	var msg matrixMessage
+	decode(t, got.body, &msg)
+	if msg.MsgType != "m.text" || !strings.Contains(msg.Body, "Error: disk full") {
+		t.Errorf("body = %q", msg.Body)
+	}
+	if !strings.Contains(msg.FormattedBody, `<a href="https://dash.example.com/jobs/web">`) ||
+		!strings.Contains(msg.FormattedBody, "<strong>Transferred:</strong> 2.0 KB") {
+		t.Errorf("formatted_body = %q", msg.FormattedBody)
+	}
+}
+
+func TestNtfy(t *testing.T) {
+	srv, got := standIn(t)
+	notifyVia(t, config.NotifyTarget{Name: "ntfy", Type: config.NotifyNtfy, URL: srv.URL + "/backups", Token: "tok", Link: testLink})
+
+	if got.path != "/backups" {
+		t.Errorf("path = %s", got.path)
+	}
+	title, err := new(mime.WordDecoder).DecodeHeader(got.header.Get("Title"))
+	if err != nil || !strings.Contains(title, `Backup "web" failed`) {
+		t.Errorf("Title = %q (%v)", got.header.Get("Title"), err)
+	}
+	for header, want := range map[string]string{
+		"Tags":          "x,keeper",
+		"Priority":      "high",
+		"Click":         "https://dash.example.com/jobs/web",
+		"Authorization": "Bearer tok",
+	} {
+		if got.header.Get(header) != want {
+			t.Errorf("%s = %q, want %q", header, got.header.Get(header), want)
+		}
+	}
+	body := string(got.body)
+	if strings.Contains(body, "❌") || !strings.Contains(body, "Duration: 1m30s") || !strings.Contains(body, "Error: disk full") {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestGotify(t *testing.T) {
+	srv, got := standIn(t)
+	notifyVia(t, config.NotifyTarget{Name: "gotify", Type: config.NotifyGotify, URL: srv.URL, Token: "app", Link: testLink})
+
+	if got.path != "/message" || got.header.Get("X-Gotify-Key") != "app" {
+		t.Errorf("path = %s, key = %q", got.path, got.header.Get("X-Gotify-Key"))
+	}
+
+	var msg struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+		Extras   struct {
+			Notification struct {
+				Click struct {
+					URL string `json:"url"`
+				} `json:"click"`
+			} `json:"client::notification"`
+		} `json:"extras"`
+	}
+	decode(t, got.body, &msg)
+	if msg.Priority != gotifyPriorityHigh || !strings.HasPrefix(msg.Title, "❌ ") {
+		t.Errorf("title = %q, priority = %d", msg.Title, msg.Priority)
+	}
+	if !strings.Contains(msg.Message, "**Error:** disk full") || !strings.Contains(msg.Message, "[Details](https://dash.example.com/jobs/web)") {
+		t.Errorf("message = %q", msg.Message)
+	}
+	if msg.Extras.Notification.Click.URL != "https://dash.example.com/jobs/web" {
+		t.Errorf("click url = %q", msg.Extras.Notification.Click.URL)
+	}
+}
+
+func TestChatSuccess(t *testing.T) {
+	srv, got := standIn(t)
+	n, _ := New(config.NotifyTarget{Name: "discord", Type: config.NotifyDiscord, URL: srv.URL})
+
+	record := failedRecord()
+	record.Success = true
+	record.Errors = nil
+	if err := n.Notify(context.Background(), RunEvent(record)); err != nil {
+		t.Fatal(err)
+	}
+
+	var msg discordMessage
+	decode(t, got.body, &msg)
+	e := msg.Embeds[0]
+	if e.Color != discordGreen || !strings.HasPrefix(e.Title, "✅ ") || e.URL != "" || len(e.Fields) != 3 {
+		t.Errorf("embed = %+v", e)
+	}
+}
+
+func TestChatBadLinkTemplate(t *testing.T) {
+	if _, err := New(config.NotifyTarget{Name: "slack", Type: config.NotifySlack, URL: "http://x", Link: "{{ .Job"}); err == nil {
+		t.Error("expected error for unparsable link template")
+	}
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+)
+
+// slowestLimit caps how many jobs a digest lists as slowest.
+const slowestLimit = 5
+
+// JobDigest is one job's share of a digest.
+type JobDigest struct {
+	Name    string
+	Stats   reporter.Stats
+	Longest time.Duration
+}
+
+// Digest summarises every job's runs over a period.
+type Digest struct {
+	Hostname string
+	Since    time.Time
+	Until    time.Time
+	Stats    reporter.Stats
+	Jobs     []JobDigest
+	Slowest  []JobDigest
+	NeverRan []string
+}
+
+// DigestFor builds the digest a target should receive at now, restricted to
+// the target's job filter and its digest period.
+func DigestFor(target config.NotifyTarget, jobs []config.Job, records []config.RunRecord, now time.Time) Digest {
+	period := config.DefaultDigestPeriod
+	if target.Digest != nil {
+		period = target.Digest.Lookback()
+	}
+
+	if len(target.Jobs) > 0 {
+		var filtered []config.Job
+		for _, job := range jobs {
+			if slices.Contains(target.Jobs, job.Name) {
+				filtered = append(filtered, job)
+			}
+		}
+		jobs = filtered
+	}
+	return BuildDigest(jobs, records, now.Add(-period), now)
+}
+
+// BuildDigest summarises records of jobs that started in [since, until).
+func BuildDigest(jobs []config.Job, records []config.RunRecord, since, until time.Time) Digest {
+	d := Digest{
+		Hostname: hostname(),
+		Since:    since,
+		Until:    until,
+	}
+
+	byJob := make(map[string][]config.RunRecord)
+	for _, r := range records {
+		if r.StartedAt.Before(since) || !r.StartedAt.Before(until) {
+			continue
+		}
+		byJob[r.JobName] = append(byJob[r.JobName], r)
+	}
+
+	// Overall stats only count runs of the jobs being reported on.
+	var counted []config.RunRecord
+	for _, job := range jobs {
+		counted = append(counted, byJob[job.Name]...)
+
+		jd := JobDigest{
+			Name:  job.Name,
+			Stats: reporter.CalculateStats(byJob[job.Name], since),
+		}
+		for _, r := range byJob[job.Name] {
+			if dur := r.CompletedAt.Sub(r.StartedAt); !r.DryRun && dur > jd.Longest {
+				jd.Longest = dur
+			}
+		}
+
+		if jd.Stats.TotalRuns == 0 {
+			d.NeverRan = append(d.NeverRan, job.Name)
+		} else {
+			d.Slowest = append(d.Slowest, jd)
+		}
+		d.Jobs = append(d.Jobs, jd)
+	}
+
+	d.Stats = reporter.CalculateStats(counted, since)
+
+	sort.SliceStable(d.Slowest, func(i, j int) bool {
+		return d.Slowest[i].Stats.AvgDuration > d.Slowest[j].Stats.AvgDuration
+	})
+	if len(d.Slowest) > slowestLimit {
+		d.Slowest = d.Slowest[:slowestLimit]
+	}
+	return d
+}
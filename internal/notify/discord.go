@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// Discord embed colours.
+const (
+	discordGreen = 0x2ecc71
+	discordRed   = 0xe74c3c
+)
+
+// Discord posts to a channel webhook as an embed.
+type Discord struct {
+	chat
+}
+
+func NewDiscord(target config.NotifyTarget) (*Discord, error) {
+	c, err := newChat(target)
+	if err != nil {
+		return nil, err
+	}
+	return &Discord{c}, nil
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title     string         `json:"title"`
+	URL       string         `json:"url,omitempty"`
+	Color     int            `json:"color"`
+	Fields    []discordField `json:"fields"`
+	Timestamp string         `json:"timestamp"`
+}
+
+type discordMessage struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+func (d *Discord) Notify(ctx context.Context, evt Event) error {
+	sum, err := d.summarise(evt)
+	if err != nil {
+		return err
+	}
+
+	embed := discordEmbed{
+		Title:     sum.Icon + " " + sum.Title,
+		URL:       sum.Link,
+		Color:     discordGreen,
+		Timestamp: evt.Time.UTC().Format(time.RFC3339),
+	}
+	if sum.Failed {
+		embed.Color = discordRed
+	}
+	for _, f := range sum.fields() {
+		embed.Fields = append(embed.Fields, discordField{Name: f[0], Value: f[1], Inline: f[0] != "Error"})
+	}
+
+	return d.postJSON(ctx, http.MethodPost, d.url, nil, discordMessage{
+		Username: "keeper",
+		Embeds:   []discordEmbed{embed},
+	})
+}
@@ -0,0 +1,269 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// Email sends alerts and digests through an SMTP server as multipart
+// plain-text and HTML messages.
+type Email struct {
+	smtp config.SMTP
+}
+
+func NewEmail(target config.NotifyTarget) (*Email, error) {
+	if target.SMTP == nil {
+		return nil, fmt.Errorf("email target %q has no smtp settings", target.Name)
+	}
+	return &Email{smtp: *target.SMTP}, nil
+}
+
+func (e *Email) Notify(ctx context.Context, evt Event) error {
+	if evt.Kind == KindStale {
+		// The message already says when the job last succeeded.
+		evt.Record = nil
+	}
+	subject := fmt.Sprintf("[keeper] %s on %s", evt.Message, evt.Hostname)
+	return e.send(ctx, subject, alertText, alertHTML, evt)
+}
+
+func (e *Email) NotifyDigest(ctx context.Context, d Digest) error {
+	subject := fmt.Sprintf("[keeper] Backup digest for %s: %.0f%% successful", d.Hostname, d.Stats.SuccessRate)
+	return e.send(ctx, subject, digestText, digestHTML, d)
+}
+
+func (e *Email) send(ctx context.Context, subject string, text *template.Template, html *htmltemplate.Template, data any) error {
+	var textBody, htmlBody bytes.Buffer
+	if err := text.Execute(&textBody, data); err != nil {
+		return fmt.Errorf("rendering text body: %w", err)
+	}
+	if err := html.Execute(&htmlBody, data); err != nil {
+		return fmt.Errorf("rendering html body: %w", err)
+	}
+
+	msg, err := buildMessage(e.smtp.From, e.smtp.To, subject, textBody.String(), htmlBody.String(), time.Now())
+	if err != nil {
+		return err
+	}
+	return e.deliver(ctx, msg)
+}
+
+// deliver runs one SMTP transaction, upgrading with STARTTLS and
+// authenticating with PLAIN when configured.
+func (e *Email) deliver(ctx context.Context, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.smtp.Addr())
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, e.smtp.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if e.smtp.StartTLS {
+		if err := c.StartTLS(&tls.Config{ServerName: e.smtp.Host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if e.smtp.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.smtp.Username, e.smtp.Password, e.smtp.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(e.smtp.From); err != nil {
+		return err
+	}
+	for _, rcpt := range e.smtp.To {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// buildMessage assembles a multipart/alternative message with text and HTML
+// parts, both quoted-printable encoded.
+func buildMessage(from string, to []string, subject, text, html string, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(pw, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qp, s); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+var emailFuncs = template.FuncMap{
+	"bytes": reporter.FormatBytes,
+	"pct":   func(f float64) string { return fmt.Sprintf("%.1f%%", f) },
+	"date":  func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"dur":   func(d time.Duration) string { return d.Round(time.Second).String() },
+}
+
+var alertText = template.Must(template.New("alert").Funcs(emailFuncs).Parse(`{{ .Message }} on {{ .Hostname }}.
+
+Job:      {{ .Job }}
+Outcome:  {{ .Outcome }}
+{{- with .Record }}
+Started:  {{ date .StartedAt }}
+Duration: {{ dur $.Duration }}
+Files:    {{ .FilesTransferred }} of {{ .FilesTotal }}
+Bytes:    {{ bytes .BytesTransferred }} of {{ bytes .BytesTotal }}
+{{- if .Errors }}
+
+Errors:
+{{- range .Errors }}
+  - {{ . }}
+{{- end }}
+{{- end }}
+{{- if .Warnings }}
+
+Warnings:
+{{- range .Warnings }}
+  - {{ . }}
+{{- end }}
+{{- end }}
+{{- end }}
+`))
+
+var alertHTML = htmltemplate.Must(htmltemplate.New("alert").Funcs(htmltemplate.FuncMap(emailFuncs)).Parse(`<html><body style="font-family: sans-serif">
+<h2>{{ .Message }} on {{ .Hostname }}</h2>
+<table cellpadding="4">
+<tr><th align="left">Job</th><td>{{ .Job }}</td></tr>
+<tr><th align="left">Outcome</th><td>{{ .Outcome }}</td></tr>
+{{- with .Record }}
+<tr><th align="left">Started</th><td>{{ date .StartedAt }}</td></tr>
+<tr><th align="left">Duration</th><td>{{ dur $.Duration }}</td></tr>
+<tr><th align="left">Files</th><td>{{ .FilesTransferred }} of {{ .FilesTotal }}</td></tr>
+<tr><th align="left">Bytes</th><td>{{ bytes .BytesTransferred }} of {{ bytes .BytesTotal }}</td></tr>
+{{- end }}
+</table>
+{{- with .Record }}{{ if .Errors }}
+<h3>Errors</h3>
+<ul>{{ range .Errors }}<li><code>{{ . }}</code></li>{{ end }}</ul>
+{{- end }}{{ if .Warnings }}
+<h3>Warnings</h3>
+<ul>{{ range .Warnings }}<li><code>{{ . }}</code></li>{{ end }}</ul>
+{{- end }}{{ end }}
+</body></html>
+`))
+
+var digestText = template.Must(template.New("digest").Funcs(emailFuncs).Parse(`Backup digest for {{ .Hostname }}
+{{ date .Since }} to {{ date .Until }}
+
+Runs:         {{ .Stats.TotalRuns }} ({{ .Stats.SuccessCount }} succeeded, {{ .Stats.WarningCount }} with warnings, {{ .Stats.FailCount }} failed)
+Success rate: {{ pct .Stats.SuccessRate }}
+Transferred:  {{ bytes .Stats.TotalBytes }}
+
+Jobs:
+{{- range .Jobs }}
+  {{ printf "%-20s" .Name }} {{ printf "%3d" .Stats.TotalRuns }} runs  {{ pct .Stats.SuccessRate }}  {{ bytes .Stats.TotalBytes }}
+{{- end }}
+{{- if .Slowest }}
+
+Slowest jobs (average duration):
+{{- range .Slowest }}
+  {{ printf "%-20s" .Name }} avg {{ dur .Stats.AvgDuration }}, longest {{ dur .Longest }}
+{{- end }}
+{{- end }}
+{{- if .NeverRan }}
+
+Jobs that did not run:
+{{- range .NeverRan }}
+  - {{ . }}
+{{- end }}
+{{- end }}
+`))
+
+var digestHTML = htmltemplate.Must(htmltemplate.New("digest").Funcs(htmltemplate.FuncMap(emailFuncs)).Parse(`<html><body style="font-family: sans-serif">
+<h2>Backup digest for {{ .Hostname }}</h2>
+<p>{{ date .Since }} to {{ date .Until }}</p>
+<p><b>{{ .Stats.TotalRuns }}</b> runs, <b>{{ pct .Stats.SuccessRate }}</b> successful
+({{ .Stats.WarningCount }} with warnings, {{ .Stats.FailCount }} failed), <b>{{ bytes .Stats.TotalBytes }}</b> transferred.</p>
+<table cellpadding="4" border="1" style="border-collapse: collapse">
+<tr><th align="left">Job</th><th>Runs</th><th>Success</th><th>Transferred</th><th>Avg duration</th></tr>
+{{- range .Jobs }}
+<tr><td>{{ .Name }}</td><td align="right">{{ .Stats.TotalRuns }}</td><td align="right">{{ pct .Stats.SuccessRate }}</td><td align="right">{{ bytes .Stats.TotalBytes }}</td><td align="right">{{ dur .Stats.AvgDuration }}</td></tr>
+{{- end }}
+</table>
+{{- if .Slowest }}
+<h3>Slowest jobs</h3>
+<ol>{{ range .Slowest }}<li>{{ .Name }}: avg {{ dur .Stats.AvgDuration }}, longest {{ dur .Longest }}</li>{{ end }}</ol>
+{{- end }}
+{{- if .NeverRan }}
+<h3>Jobs that did not run</h3>
+<ul>{{ range .NeverRan }}<li>{{ . }}</li>{{ end }}</ul>
+{{- end }}
+</body></html>
+`))
@@ -0,0 +1,206 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// fakeSMTP accepts one message on a local port and returns what it received.
+func fakeSMTP(t *testing.T) (config.SMTP, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		reply("220 localhost ready")
+
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case strings.HasPrefix(cmd, "AUTH PLAIN"):
+				reply("235 ok")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				reply("250 ok")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				received <- data.String()
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return config.SMTP{
+		Host:     "localhost",
+		Port:     p,
+		Username: "keeper",
+		Password: "secret",
+		From:     "keeper@example.com",
+		To:       []string{"ops@example.com"},
+	}, received
+}
+
+// parts returns the text and HTML bodies of a multipart/alternative message.
+func parts(t *testing.T, raw string) (*mail.Message, map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q", msg.Header.Get("Content-Type"))
+	}
+
+	bodies := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		data, _ := io.ReadAll(p) // quoted-printable is decoded by NextPart
+		bodies[ct] = strings.ReplaceAll(string(data), "\r\n", "\n")
+	}
+	return msg, bodies
+}
+
+func TestEmailAlert(t *testing.T) {
+	settings, received := fakeSMTP(t)
+	e, err := NewEmail(config.NotifyTarget{Name: "mail", Type: config.NotifyEmail, SMTP: &settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.Notify(ctx, RunEvent(failedRecord())); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, bodies := parts(t, <-received)
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if !strings.Contains(subject, `Backup "web" failed`) {
+		t.Errorf("Subject = %q", subject)
+	}
+	if !strings.Contains(bodies["text/plain"], "disk full") {
+		t.Errorf("text body missing error:\n%s", bodies["text/plain"])
+	}
+	if !strings.Contains(bodies["text/html"], "<code>disk full</code>") {
+		t.Errorf("html body missing error:\n%s", bodies["text/html"])
+	}
+}
+
+func TestEmailDigest(t *testing.T) {
+	settings, received := fakeSMTP(t)
+	e, _ := NewEmail(config.NotifyTarget{Name: "mail", Type: config.NotifyEmail, SMTP: &settings})
+
+	now := time.Date(2026, 3, 8, 8, 0, 0, 0, time.UTC)
+	jobs := []config.Job{{Name: "web"}, {Name: "db"}}
+	records := []config.RunRecord{
+		{JobName: "web", StartedAt: now.Add(-time.Hour), CompletedAt: now.Add(-50 * time.Minute), Success: true, BytesTransferred: 1 << 20},
+	}
+	dg := BuildDigest(jobs, records, now.Add(-7*24*time.Hour), now)
+
+	if err := e.NotifyDigest(context.Background(), dg); err != nil {
+		t.Fatal(err)
+	}
+
+	_, bodies := parts(t, <-received)
+	text := bodies["text/plain"]
+	for _, want := range []string{"Success rate: 100.0%", "1.0 MB", "Jobs that did not run:\n  - db"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text body missing %q:\n%s", want, text)
+		}
+	}
+	if !strings.Contains(bodies["text/html"], "<li>db</li>") {
+		t.Errorf("html body missing never-run job:\n%s", bodies["text/html"])
+	}
+}
+
+func TestBuildDigest(t *testing.T) {
+	now := time.Date(2026, 3, 8, 8, 0, 0, 0, time.UTC)
+	since := now.Add(-24 * time.Hour)
+	run := func(job string, ago, dur time.Duration, ok bool) config.RunRecord {
+		start := now.Add(-ago)
+		return config.RunRecord{JobName: job, StartedAt: start, CompletedAt: start.Add(dur), Success: ok, BytesTransferred: 100}
+	}
+
+	records := []config.RunRecord{
+		run("web", 2*time.Hour, time.Minute, true),
+		run("web", time.Hour, 3*time.Minute, false),
+		run("db", 3*time.Hour, 10*time.Minute, true),
+		run("db", 48*time.Hour, time.Hour, true), // before the period
+		run("other", time.Hour, time.Hour, true), // not a reported job
+		{JobName: "web", StartedAt: now.Add(-time.Minute), CompletedAt: now, DryRun: true},
+	}
+	jobs := []config.Job{{Name: "web"}, {Name: "db"}, {Name: "idle"}}
+
+	d := BuildDigest(jobs, records, since, now)
+
+	if d.Stats.TotalRuns != 3 || d.Stats.FailCount != 1 || d.Stats.TotalBytes != 300 {
+		t.Errorf("overall stats = %+v", d.Stats)
+	}
+	if len(d.NeverRan) != 1 || d.NeverRan[0] != "idle" {
+		t.Errorf("NeverRan = %v", d.NeverRan)
+	}
+	if len(d.Slowest) != 2 || d.Slowest[0].Name != "db" || d.Slowest[1].Longest != 3*time.Minute {
+		t.Errorf("Slowest = %+v", d.Slowest)
+	}
+
+	filtered := DigestFor(config.NotifyTarget{Jobs: []string{"web"}, Digest: &config.Digest{Period: 24 * time.Hour}}, jobs, records, now)
+	if len(filtered.Jobs) != 1 || filtered.Stats.TotalRuns != 2 {
+		t.Errorf("filtered digest = %+v", filtered)
+	}
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// Gotify message priorities; 8 and above make the Android app alert loudly.
+const (
+	gotifyPriorityNormal = 4
+	gotifyPriorityHigh   = 8
+)
+
+// Gotify posts a markdown message to a Gotify server with an app token.
+type Gotify struct {
+	chat
+}
+
+func NewGotify(target config.NotifyTarget) (*Gotify, error) {
+	c, err := newChat(target)
+	if err != nil {
+		return nil, err
+	}
+	return &Gotify{c}, nil
+}
+
+type gotifyMessage struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras"`
+}
+
+func (g *Gotify) Notify(ctx context.Context, evt Event) error {
+	sum, err := g.summarise(evt)
+	if err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	for _, f := range sum.fields() {
+		fmt.Fprintf(&body, "**%s:** %s  \n", f[0], f[1])
+	}
+	if sum.Link != "" {
+		fmt.Fprintf(&body, "\n[Details](%s)", sum.Link)
+	}
+
+	msg := gotifyMessage{
+		Title:    sum.Icon + " " + sum.Title,
+		Message:  strings.TrimSpace(body.String()),
+		Priority: gotifyPriorityNormal,
+		Extras: map[string]any{
+			"client::display": map[string]string{"contentType": "text/markdown"},
+		},
+	}
+	if sum.Failed {
+		msg.Priority = gotifyPriorityHigh
+	}
+	if sum.Link != "" {
+		msg.Extras["client::notification"] = map[string]any{"click": map[string]string{"url": sum.Link}}
+	}
+
+	return g.postJSON(ctx, http.MethodPost, g.url+"/message", map[string]string{"X-Gotify-Key": g.token}, msg)
+}
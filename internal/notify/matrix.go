@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// Matrix sends an m.room.message to a room through the client-server API,
+// authenticated with the account's access token.
+type Matrix struct {
+	chat
+	room string
+}
+
+func NewMatrix(target config.NotifyTarget) (*Matrix, error) {
+	c, err := newChat(target)
+	if err != nil {
+		return nil, err
+	}
+	return &Matrix{chat: c, room: target.Room}, nil
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// matrixTxn keeps transaction IDs unique within the process; the homeserver
+// uses them to drop duplicate sends on retry.
+var matrixTxn atomic.Uint64
+
+func (m *Matrix) Notify(ctx context.Context, evt Event) error {
+	sum, err := m.summarise(evt)
+	if err != nil {
+		return err
+	}
+
+	var formatted strings.Builder
+	title := html.EscapeString(sum.Title)
+	if sum.Link != "" {
+		title = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(sum.Link), title)
+	}
+	fmt.Fprintf(&formatted, "%s <strong>%s</strong>", sum.Icon, title)
+	for _, f := range sum.fields() {
+		fmt.Fprintf(&formatted, "<br><strong>%s:</strong> %s", f[0], html.EscapeString(f[1]))
+	}
+
+	txn := fmt.Sprintf("keeper-%d-%d", time.Now().UnixNano(), matrixTxn.Add(1))
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.url, url.PathEscape(m.room), txn)
+
+	return m.postJSON(ctx, http.MethodPut, endpoint, map[string]string{"Authorization": "Bearer " + m.token}, matrixMessage{
+		MsgType:       "m.text",
+		Body:          sum.text(),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatted.String(),
+	})
+}
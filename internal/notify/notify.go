@@ -0,0 +1,335 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+)
+
+// Event kinds.
+const (
+	KindRun     = "run"
+	KindTest    = "test"
+	KindStale   = "stale"
+	KindAnomaly = "anomaly"
+)
+
+const defaultRetries = 3
+
+// retryBackoff is the delay before the first retry; it doubles each attempt.
+var retryBackoff = time.Second
+
+// deliveryTimeout bounds one delivery to a target, retries included, so an
+// unreachable target can't hold up the run or command that sent it for long.
+var deliveryTimeout = 15 * time.Second
+
+// attemptShare is the fraction of deliveryTimeout one attempt may take, so a
+// target that hangs rather than refuses still gets retried.
+const attemptShare = 4
+
+// Event is what notifiers render. For run events Record holds the finished run.
+type Event struct {
+	Kind     string            `json:"kind"`
+	Job      string            `json:"job"`
+	Outcome  string            `json:"outcome"`
+	Hostname string            `json:"hostname"`
+	Time     time.Time         `json:"time"`
+	Message  string            `json:"message"`
+	Record   *config.RunRecord `json:"record,omitempty"`
+}
+
+// RunEvent builds the notification for a completed run.
+func RunEvent(record config.RunRecord) Event {
+	outcome := Outcome(record)
+	msg := fmt.Sprintf("Backup %q succeeded", record.JobName)
+	switch outcome {
+	case config.OutcomeWarning:
+		msg = fmt.Sprintf("Backup %q succeeded with warnings", record.JobName)
+	case config.OutcomeFailure:
+		msg = fmt.Sprintf("Backup %q failed", record.JobName)
+	}
+
+	return Event{
+		Kind:     KindRun,
+		Job:      record.JobName,
+		Outcome:  outcome,
+		Hostname: hostname(),
+		Time:     record.CompletedAt,
+		Message:  msg,
+		Record:   &record,
+	}
+}
+
+// StaleEvent builds the alert for a job whose last success is older than its
+// max_age. Record is the last successful run, if there was one.
+func StaleEvent(jobName string, rpo reporter.RPO, now time.Time) Event {
+	msg := fmt.Sprintf("Backup %q has never succeeded (max age %s)", jobName, rpo.MaxAge)
+	if rpo.LastSuccess != nil {
+		msg = fmt.Sprintf("Backup %q is overdue: last success %s ago (max age %s)",
+			jobName, rpo.Age.Round(time.Minute), rpo.MaxAge)
+	}
+
+	return Event{
+		Kind:     KindStale,
+		Job:      jobName,
+		Outcome:  config.OutcomeStale,
+		Hostname: hostname(),
+		Time:     now,
+		Message:  msg,
+		Record:   rpo.LastSuccess,
+	}
+}
+
+// AnomalyEvent builds the alert for a run whose size fell outside the job's
+// baseline. It is sent in addition to the run's own event.
+func AnomalyEvent(record config.RunRecord) Event {
+	var changes []string
+	for _, a := range record.Anomalies {
+		changes = append(changes, DescribeAnomaly(a))
+	}
+
+	return Event{
+		Kind:     KindAnomaly,
+		Job:      record.JobName,
+		Outcome:  config.OutcomeAnomaly,
+		Hostname: hostname(),
+		Time:     record.CompletedAt,
+		Message:  fmt.Sprintf("Backup %q size is unusual: %s", record.JobName, strings.Join(changes, ", ")),
+		Record:   &record,
+	}
+}
+
+// DescribeAnomaly renders an anomaly as e.g. "total size 2.0 GB vs usual
+// 10.0 GB (-80%)".
+func DescribeAnomaly(a config.SizeAnomaly) string {
+	if a.Metric == "files_total" {
+		return fmt.Sprintf("%d files vs usual %d (%+.0f%%)", a.Value, a.Baseline, a.Change)
+	}
+	return fmt.Sprintf("total size %s vs usual %s (%+.0f%%)", reporter.FormatBytes(a.Value), reporter.FormatBytes(a.Baseline), a.Change)
+}
+
+// SampleEvent is sent by 'keeper notify test'.
+func SampleEvent() Event {
+	now := time.Now()
+	record := config.RunRecord{
+		JobName:          "example",
+		StartedAt:        now.Add(-2 * time.Minute),
+		CompletedAt:      now,
+		Success:          false,
+		FilesTotal:       1200,
+		FilesTransferred: 42,
+		BytesTotal:       5 << 30,
+		BytesTransferred: 12 << 20,
+		Errors:           []string{"rsync exited with code 255: SSH connection failed — check host, port, and SSH key"},
+		Trigger:          config.TriggerManual,
+	}
+	evt := RunEvent(record)
+	evt.Kind = KindTest
+	evt.Message = "Test notification from Keeper"
+	return evt
+}
+
+// Outcome classifies a run for notification filters.
+func Outcome(r config.RunRecord) string {
+	return r.Status()
+}
+
+// Duration is the run's wall time, for templates.
+func (e Event) Duration() time.Duration {
+	if e.Record == nil {
+		return 0
+	}
+	return e.Record.CompletedAt.Sub(e.Record.StartedAt).Round(time.Second)
+}
+
+// FirstError is the first recorded error of the run, if any.
+func (e Event) FirstError() string {
+	if e.Record == nil || len(e.Record.Errors) == 0 {
+		return ""
+	}
+	return e.Record.Errors[0]
+}
+
+func hostname() string {
+	h, _ := os.Hostname()
+	return h
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, evt Event) error
+}
+
+// DigestNotifier is implemented by notifiers that can send period summaries.
+type DigestNotifier interface {
+	NotifyDigest(ctx context.Context, d Digest) error
+}
+
+// New builds the notifier for a configured target.
+func New(target config.NotifyTarget) (Notifier, error) {
+	switch target.Type {
+	case config.NotifyWebhook:
+		return NewWebhook(target)
+	case config.NotifyEmail:
+		return NewEmail(target)
+	case config.NotifySlack:
+		return NewSlack(target)
+	case config.NotifyDiscord:
+		return NewDiscord(target)
+	case config.NotifyMatrix:
+		return NewMatrix(target)
+	case config.NotifyNtfy:
+		return NewNtfy(target)
+	case config.NotifyGotify:
+		return NewGotify(target)
+	default:
+		return nil, fmt.Errorf("unknown notification type: %q", target.Type)
+	}
+}
+
+type destination struct {
+	target   config.NotifyTarget
+	notifier Notifier
+}
+
+// Dispatcher fans events out to every matching target, retrying failed
+// deliveries with backoff.
+type Dispatcher struct {
+	targets []destination
+	jobs    []config.Job
+}
+
+// NewDispatcher builds notifiers for all targets. Targets that fail to build
+// are logged and skipped so one bad template doesn't silence the rest.
+func NewDispatcher(cfg *config.Config) *Dispatcher {
+	d := &Dispatcher{jobs: cfg.Jobs}
+	for _, t := range cfg.Notifications.Targets {
+		n, err := New(t)
+		if err != nil {
+			slog.Error("notification target disabled", "target", t.Name, "error", err)
+			continue
+		}
+		d.targets = append(d.targets, destination{target: t, notifier: n})
+	}
+	return d
+}
+
+// Dispatch delivers evt to every target whose filters match. Targets are
+// sent to in parallel, so Dispatch returns within deliveryTimeout.
+func (d *Dispatcher) Dispatch(ctx context.Context, evt Event) {
+	if d == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, dest := range d.targets {
+		if !Matches(dest.target, evt) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Send(ctx, dest.target, dest.notifier, evt); err != nil {
+				slog.Error("notification failed", "target", dest.target.Name, "job", evt.Job, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// DispatchRun announces a finished run, followed by an anomaly alert if the
+// run's size was unusual.
+func (d *Dispatcher) DispatchRun(ctx context.Context, record config.RunRecord) {
+	d.Dispatch(ctx, RunEvent(record))
+	if len(record.Anomalies) > 0 {
+		d.Dispatch(ctx, AnomalyEvent(record))
+	}
+}
+
+// Send delivers evt to one target, ignoring its filters.
+func Send(ctx context.Context, target config.NotifyTarget, n Notifier, evt Event) error {
+	return deliver(ctx, target, func(ctx context.Context) error { return n.Notify(ctx, evt) })
+}
+
+// deliver calls send until it succeeds, the target's retries run out or
+// deliveryTimeout passes. Each attempt gets a share of deliveryTimeout.
+func deliver(ctx context.Context, target config.NotifyTarget, send func(context.Context) error) error {
+	retries := defaultRetries
+	if target.Retries != nil {
+		retries = *target.Retries
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	var err error
+	delay := retryBackoff
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			slog.Warn("retrying notification", "target", target.Name, "attempt", attempt, "error", err)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return fmt.Errorf("gave up after %d attempt(s): %w", attempt, err)
+			}
+			delay *= 2
+		}
+		attemptCtx, cancelAttempt := context.WithTimeout(ctx, deliveryTimeout/attemptShare)
+		err = send(attemptCtx)
+		cancelAttempt()
+		if err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("after %d attempt(s): %w", retries+1, err)
+}
+
+// DigestTargets returns the targets with a digest schedule.
+func (d *Dispatcher) DigestTargets() []config.NotifyTarget {
+	if d == nil {
+		return nil
+	}
+	var targets []config.NotifyTarget
+	for _, dest := range d.targets {
+		if dest.target.Digest != nil {
+			targets = append(targets, dest.target)
+		}
+	}
+	return targets
+}
+
+// SendDigest summarises records for the named target and delivers it.
+func (d *Dispatcher) SendDigest(ctx context.Context, name string, records []config.RunRecord, now time.Time) error {
+	for _, dest := range d.targets {
+		if dest.target.Name == name {
+			return SendDigest(ctx, dest.target, dest.notifier, DigestFor(dest.target, d.jobs, records, now))
+		}
+	}
+	return fmt.Errorf("notification target %q not found", name)
+}
+
+// SendDigest delivers a digest to one target.
+func SendDigest(ctx context.Context, target config.NotifyTarget, n Notifier, dg Digest) error {
+	dn, ok := n.(DigestNotifier)
+	if !ok {
+		return fmt.Errorf("%s targets do not support digests", target.Type)
+	}
+	return deliver(ctx, target, func(ctx context.Context) error { return dn.NotifyDigest(ctx, dg) })
+}
+
+// Matches reports whether the target's job and outcome filters accept evt.
+func Matches(target config.NotifyTarget, evt Event) bool {
+	if len(target.Jobs) > 0 && !slices.Contains(target.Jobs, evt.Job) {
+		return false
+	}
+	if on := target.Outcomes(); len(on) > 0 && !slices.Contains(on, evt.Outcome) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+func failedRecord() config.RunRecord {
+	start := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	return config.RunRecord{
+		JobName:          "web",
+		StartedAt:        start,
+		CompletedAt:      start.Add(90 * time.Second),
+		Success:          false,
+		BytesTransferred: 2048,
+		Errors:           []string{"disk full", "second"},
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	var gotBody, gotAuth, gotType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		gotAuth = r.Header.Get("Authorization")
+		gotType = r.Header.Get("Content-Type")
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhook(config.NotifyTarget{
+		Name:    "hook",
+		URL:     srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		Body:    `{"text": {{ printf "%s %s in %s: %s" (upper .Outcome) .Job .Duration .FirstError | json }}, "bytes": {{ json (bytes .Record.BytesTransferred) }}}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Notify(context.Background(), RunEvent(failedRecord())); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"text": "FAILURE web in 1m30s: disk full", "bytes": "2.0 KB"}`
+	if gotBody != want {
+		t.Errorf("body = %s, want %s", gotBody, want)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotType != "application/json" {
+		t.Errorf("Content-Type = %q", gotType)
+	}
+}
+
+func TestWebhookDefaultBody(t *testing.T) {
+	var evt Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&evt)
+	}))
+	defer srv.Close()
+
+	w, err := NewWebhook(config.NotifyTarget{Name: "hook", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Notify(context.Background(), RunEvent(failedRecord())); err != nil {
+		t.Fatal(err)
+	}
+	if evt.Job != "web" || evt.Outcome != config.OutcomeFailure || evt.Record == nil {
+		t.Errorf("decoded event = %+v", evt)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = time.Second }()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	target := config.NotifyTarget{Name: "hook", URL: srv.URL}
+	w, _ := NewWebhook(target)
+	if err := Send(context.Background(), target, w, SampleEvent()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+
+	none := 0
+	target.Retries = &none
+	calls.Store(0)
+	if err := Send(context.Background(), target, w, SampleEvent()); err == nil {
+		t.Error("expected error with retries disabled")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestDispatchGivesUp(t *testing.T) {
+	deliveryTimeout = 100 * time.Millisecond
+	defer func() { deliveryTimeout = 15 * time.Second }()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	// With the default one-second backoff, three unreachable targets would
+	// otherwise take seven seconds each.
+	cfg := &config.Config{Notifications: config.Notifications{Targets: []config.NotifyTarget{
+		{Name: "a", Type: config.NotifyWebhook, URL: srv.URL},
+		{Name: "b", Type: config.NotifyWebhook, URL: srv.URL},
+		{Name: "c", Type: config.NotifyWebhook, URL: srv.URL},
+	}}}
+	start := time.Now()
+	NewDispatcher(cfg).Dispatch(context.Background(), SampleEvent())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Dispatch took %v, want it bounded by the delivery timeout", elapsed)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want one per target", calls.Load())
+	}
+}
+
+func TestSendRetriesHangingTarget(t *testing.T) {
+	retryBackoff = time.Millisecond
+	deliveryTimeout = 200 * time.Millisecond
+	defer func() { retryBackoff, deliveryTimeout = time.Second, 15*time.Second }()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	target := config.NotifyTarget{Name: "hook", URL: srv.URL}
+	w, _ := NewWebhook(target)
+	if err := Send(context.Background(), target, w, SampleEvent()); err == nil {
+		t.Error("expected error from a hanging target")
+	}
+	if calls.Load() < 2 {
+		t.Errorf("calls = %d, want a retry after the first attempt timed out", calls.Load())
+	}
+}
+
+func TestMatches(t *testing.T) {
+	evt := RunEvent(failedRecord())
+
+	cases := []struct {
+		target config.NotifyTarget
+		want   bool
+	}{
+		{config.NotifyTarget{}, true},
+		{config.NotifyTarget{Jobs: []string{"web"}}, true},
+		{config.NotifyTarget{Jobs: []string{"db"}}, false},
+		{config.NotifyTarget{On: []string{config.OutcomeFailure}}, true},
+		{config.NotifyTarget{On: []string{config.OutcomeSuccess}}, false},
+		{config.NotifyTarget{Jobs: []string{"web"}, On: []string{config.OutcomeSuccess}}, false},
+	}
+	for i, c := range cases {
+		if got := Matches(c.target, evt); got != c.want {
+			t.Errorf("case %d: Matches = %v, want %v", i, got, c.want)
+		}
+	}
+}
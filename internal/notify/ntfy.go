@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// Ntfy publishes to a topic URL. The title, tags, priority and click link
+// travel in ntfy's headers; the body is the plain-text summary.
+type Ntfy struct {
+	chat
+}
+
+func NewNtfy(target config.NotifyTarget) (*Ntfy, error) {
+	c, err := newChat(target)
+	if err != nil {
+		return nil, err
+	}
+	return &Ntfy{c}, nil
+}
+
+func (n *Ntfy) Notify(ctx context.Context, evt Event) error {
+	sum, err := n.summarise(evt)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{
+		// Header values must be ASCII; ntfy decodes RFC 2047 words.
+		"Title":    mime.QEncoding.Encode("utf-8", sum.Title),
+		"Tags":     "white_check_mark,keeper",
+		"Priority": "default",
+	}
+	if sum.Failed {
+		headers["Tags"] = "x,keeper"
+		headers["Priority"] = "high"
+	}
+	if sum.Link != "" {
+		headers["Click"] = sum.Link
+	}
+	if n.token != "" {
+		headers["Authorization"] = "Bearer " + n.token
+	}
+
+	// The icon is carried by the tag, so drop it from the first line.
+	body := strings.TrimPrefix(sum.text(), sum.Icon+" ")
+	return post(ctx, n.client, http.MethodPost, n.url, n.withHeaders(headers), "text/plain; charset=utf-8", []byte(body))
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// Slack posts to an incoming webhook as a colour-coded attachment.
+type Slack struct {
+	chat
+}
+
+func NewSlack(target config.NotifyTarget) (*Slack, error) {
+	c, err := newChat(target)
+	if err != nil {
+		return nil, err
+	}
+	return &Slack{c}, nil
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color     string       `json:"color"`
+	Title     string       `json:"title"`
+	TitleLink string       `json:"title_link,omitempty"`
+	Fields    []slackField `json:"fields"`
+	Footer    string       `json:"footer"`
+	Timestamp int64        `json:"ts"`
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+func (s *Slack) Notify(ctx context.Context, evt Event) error {
+	sum, err := s.summarise(evt)
+	if err != nil {
+		return err
+	}
+
+	att := slackAttachment{
+		Color:     "good",
+		Title:     sum.Icon + " " + sum.Title,
+		TitleLink: sum.Link,
+		Footer:    "keeper",
+		Timestamp: evt.Time.Unix(),
+	}
+	if sum.Failed {
+		att.Color = "danger"
+	}
+	for _, f := range sum.fields() {
+		att.Fields = append(att.Fields, slackField{Title: f[0], Value: f[1], Short: f[0] != "Error"})
+	}
+
+	return s.postJSON(ctx, http.MethodPost, s.url, nil, slackMessage{
+		Text:        sum.Icon + " " + sum.Title,
+		Attachments: []slackAttachment{att},
+	})
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/reporter"
+)
+
+// Webhook posts events to a URL. The body is the target's Go template, or the
+// event as JSON when no template is set.
+type Webhook struct {
+	url     string
+	method  string
+	headers map[string]string
+	body    *template.Template
+	client  *http.Client
+}
+
+func NewWebhook(target config.NotifyTarget) (*Webhook, error) {
+	w := &Webhook{
+		url:     target.URL,
+		method:  target.Method,
+		headers: target.Headers,
+		client:  &http.Client{},
+	}
+	if w.method == "" {
+		w.method = http.MethodPost
+	}
+
+	if target.Body != "" {
+		tmpl, err := template.New(target.Name).Funcs(templateFuncs).Parse(target.Body)
+		if err != nil {
+			return nil, fmt.Errorf("parsing body template: %w", err)
+		}
+		w.body = tmpl
+	}
+	return w, nil
+}
+
+func (w *Webhook) Notify(ctx context.Context, evt Event) error {
+	var body bytes.Buffer
+	contentType := "application/json"
+
+	if w.body != nil {
+		if err := w.body.Execute(&body, evt); err != nil {
+			return fmt.Errorf("rendering body: %w", err)
+		}
+		if !json.Valid(body.Bytes()) {
+			contentType = "text/plain; charset=utf-8"
+		}
+	} else if err := json.NewEncoder(&body).Encode(evt); err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+
+	return post(ctx, w.client, w.method, w.url, w.headers, contentType, body.Bytes())
+}
+
+// post sends body and treats any non-2xx status as an error.
+func post(ctx context.Context, client *http.Client, method, url string, headers map[string]string, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "keeper")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s %s", method, url, resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+var templateFuncs = template.FuncMap{
+	// json renders a value as a JSON literal, for safely embedding strings.
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"bytes": reporter.FormatBytes,
+	"upper": strings.ToUpper,
+}
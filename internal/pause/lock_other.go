@@ -0,0 +1,9 @@
+//go:build !unix
+
+package pause
+
+// lockFile is a no-op where flock is unavailable; the state file is still
+// replaced atomically, but concurrent changes may overwrite each other.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
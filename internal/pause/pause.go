@@ -0,0 +1,166 @@
+package pause
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// Entry records when a pause started and, optionally, when it lapses.
+type Entry struct {
+	PausedAt time.Time `json:"paused_at"`
+	Until    time.Time `json:"until,omitempty"`
+}
+
+func (e Entry) active(now time.Time) bool {
+	return e.Until.IsZero() || now.Before(e.Until)
+}
+
+// State is the persisted set of paused jobs. All pauses every job.
+type State struct {
+	All  *Entry           `json:"all,omitempty"`
+	Jobs map[string]Entry `json:"jobs,omitempty"`
+}
+
+// Paused reports whether the job is paused at now, returning the pause that
+// applies. When both a global and a per-job pause are active, the one that
+// lasts longer wins.
+func (st State) Paused(jobName string, now time.Time) (Entry, bool) {
+	var found Entry
+	ok := false
+
+	if st.All != nil && st.All.active(now) {
+		found, ok = *st.All, true
+	}
+	if e, exists := st.Jobs[jobName]; exists && e.active(now) {
+		if !ok || (!found.Until.IsZero() && (e.Until.IsZero() || e.Until.After(found.Until))) {
+			found, ok = e, true
+		}
+	}
+	return found, ok
+}
+
+type Store struct {
+	path string
+}
+
+func NewStore() *Store {
+	return &Store{
+		path: filepath.Join(config.DataDir(), "paused.json"),
+	}
+}
+
+func NewStoreAt(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load reads the pause state. A missing file means nothing is paused.
+func (s *Store) Load() (State, error) {
+	var st State
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return st, fmt.Errorf("reading pause state: %w", err)
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("parsing pause state: %w", err)
+	}
+	return st, nil
+}
+
+// Pause pauses one job, or every job when jobName is empty. A zero until
+// pauses indefinitely.
+func (s *Store) Pause(jobName string, until time.Time) error {
+	return s.update(func(st *State) error {
+		e := Entry{PausedAt: time.Now(), Until: until}
+		if jobName == "" {
+			st.All = &e
+		} else {
+			if st.Jobs == nil {
+				st.Jobs = make(map[string]Entry)
+			}
+			st.Jobs[jobName] = e
+		}
+		return nil
+	})
+}
+
+// ErrAllPaused is returned when resuming one job while every job is paused;
+// the global pause can only be lifted as a whole.
+var ErrAllPaused = errors.New("all jobs are paused")
+
+// Resume lifts the pause on one job, or on everything when jobName is empty.
+// Resuming one job while all jobs are paused fails with ErrAllPaused and
+// changes nothing.
+func (s *Store) Resume(jobName string) error {
+	return s.update(func(st *State) error {
+		if jobName != "" && st.All != nil && st.All.active(time.Now()) {
+			return ErrAllPaused
+		}
+		if jobName == "" {
+			*st = State{}
+		} else {
+			delete(st.Jobs, jobName)
+		}
+		return nil
+	})
+}
+
+// update applies fn to the current state and saves the result, holding the
+// state's lock throughout so concurrent changes from the CLI and the daemon
+// don't overwrite each other. Nothing is saved if fn fails.
+func (s *Store) update(fn func(*State) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating data dir: %w", err)
+	}
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("locking pause state: %w", err)
+	}
+	defer unlock()
+
+	st, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(&st); err != nil {
+		return err
+	}
+	return s.save(st)
+}
+
+// save prunes lapsed pauses and writes the state atomically. The caller
+// must hold the state's lock.
+func (s *Store) save(st State) error {
+	now := time.Now()
+	if st.All != nil && !st.All.active(now) {
+		st.All = nil
+	}
+	for name, e := range st.Jobs {
+		if !e.active(now) {
+			delete(st.Jobs, name)
+		}
+	}
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling pause state: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing pause state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("writing pause state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package pause
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPauseResume(t *testing.T) {
+	s := NewStoreAt(filepath.Join(t.TempDir(), "paused.json"))
+	now := time.Now()
+
+	st, err := s.Load()
+	if err != nil {
+		t.Fatalf("load empty: %v", err)
+	}
+	if _, paused := st.Paused("web", now); paused {
+		t.Fatal("nothing should be paused initially")
+	}
+
+	if err := s.Pause("web", time.Time{}); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if err := s.Pause("db", now.Add(time.Hour)); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+
+	st, _ = s.Load()
+	if _, paused := st.Paused("web", now); !paused {
+		t.Error("web should be paused indefinitely")
+	}
+	if _, paused := st.Paused("db", now); !paused {
+		t.Error("db should be paused for an hour")
+	}
+	if _, paused := st.Paused("db", now.Add(2*time.Hour)); paused {
+		t.Error("db pause should have lapsed")
+	}
+	if _, paused := st.Paused("other", now); paused {
+		t.Error("other should not be paused")
+	}
+
+	if err := s.Resume("web"); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	st, _ = s.Load()
+	if _, paused := st.Paused("web", now); paused {
+		t.Error("web should be resumed")
+	}
+	if _, paused := st.Paused("db", now); !paused {
+		t.Error("db should still be paused")
+	}
+}
+
+func TestPauseAll(t *testing.T) {
+	s := NewStoreAt(filepath.Join(t.TempDir(), "paused.json"))
+	now := time.Now()
+
+	if err := s.Pause("", now.Add(time.Hour)); err != nil {
+		t.Fatalf("pause all: %v", err)
+	}
+	if err := s.Pause("web", time.Time{}); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+
+	st, _ := s.Load()
+	e, paused := st.Paused("anything", now)
+	if !paused || e.Until.IsZero() {
+		t.Errorf("expected global timed pause, got %+v paused=%v", e, paused)
+	}
+	// The indefinite job pause outlasts the global one.
+	if e, _ := st.Paused("web", now); !e.Until.IsZero() {
+		t.Errorf("expected indefinite pause for web, got until %v", e.Until)
+	}
+
+	if err := s.Resume("web"); !errors.Is(err, ErrAllPaused) {
+		t.Errorf("resume one job under a global pause: err = %v, want ErrAllPaused", err)
+	}
+	if st, _ := s.Load(); len(st.Jobs) != 1 {
+		t.Errorf("failed resume changed the state: %+v", st)
+	}
+
+	if err := s.Resume(""); err != nil {
+		t.Fatalf("resume all: %v", err)
+	}
+	st, _ = s.Load()
+	if _, paused := st.Paused("web", now); paused {
+		t.Error("resume --all should clear job pauses too")
+	}
+}
+
+func TestConcurrentPauses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "paused.json")
+
+	// Separate stores stand in for the CLI and the daemon.
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := NewStoreAt(path).Pause(fmt.Sprintf("job%d", i), time.Time{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	st, err := NewStoreAt(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Jobs) != 20 {
+		t.Errorf("%d of 20 pauses kept", len(st.Jobs))
+	}
+}
@@ -0,0 +1,125 @@
+package ping
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// maxBody caps the fail ping's body; healthchecks.io keeps the first 100 KB.
+const maxBody = 10 << 10
+
+// Pinger sends healthchecks.io-style pings for one run of a job. All pings
+// are best effort: failures are logged and never affect the run. A nil
+// Pinger does nothing, so callers need not check whether pings are set up.
+type Pinger struct {
+	job     string
+	runID   string
+	cfg     config.Ping
+	client  *http.Client
+	started chan struct{}
+}
+
+// New returns a Pinger for the job's ping settings, or nil if it has none.
+func New(job *config.Job, runID string) *Pinger {
+	if job.Ping == nil {
+		return nil
+	}
+	return &Pinger{
+		job:    job.Name,
+		runID:  runID,
+		cfg:    *job.Ping,
+		client: &http.Client{Timeout: job.Ping.PingTimeout()},
+	}
+}
+
+// Start sends the start ping in the background so the run isn't delayed.
+func (p *Pinger) Start() {
+	if p == nil {
+		return
+	}
+	p.started = make(chan struct{})
+	go func() {
+		defer close(p.started)
+		p.send("start", p.cfg.StartURL(), "")
+	}()
+}
+
+// Finish sends the success or fail ping. It waits for the start ping first
+// so the monitor sees them in order; both are bounded by the ping timeout.
+func (p *Pinger) Finish(success bool, summary string) {
+	if p == nil {
+		return
+	}
+	if p.started != nil {
+		<-p.started
+	}
+	if success {
+		p.send("success", p.cfg.SuccessURL(), "")
+		return
+	}
+
+	body := fmt.Sprintf("keeper run %s of job %q failed\n\n%s", p.runID, p.job, summary)
+	if len(body) > maxBody {
+		body = body[:maxBody]
+	}
+	p.send("fail", p.cfg.FailURL(), body)
+}
+
+func (p *Pinger) send(kind, target, body string) {
+	if target == "" {
+		return
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		slog.Warn("invalid ping url", "job", p.job, "ping", kind, "error", err)
+		return
+	}
+	// rid lets healthchecks.io pair start and end pings of the same run.
+	q := u.Query()
+	q.Set("rid", p.runID)
+	u.RawQuery = q.Encode()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.PingTimeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(body))
+	if err != nil {
+		slog.Warn("building ping", "job", p.job, "ping", kind, "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "keeper")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		slog.Warn("ping failed", "job", p.job, "ping", kind, "error", err)
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		slog.Warn("ping rejected", "job", p.job, "ping", kind, "status", resp.Status)
+		return
+	}
+	slog.Debug("ping sent", "job", p.job, "ping", kind, "run_id", p.runID)
+}
+
+// NewRunID returns a random RFC 4122 version 4 UUID, the run ID format
+// healthchecks.io expects.
+func NewRunID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,115 @@
+package ping
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+type hit struct {
+	path string
+	rid  string
+	body string
+}
+
+func monitor(t *testing.T, handler func()) (*httptest.Server, func() []hit) {
+	t.Helper()
+	var mu sync.Mutex
+	var hits []hit
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handler != nil {
+			handler()
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		hits = append(hits, hit{path: r.URL.Path, rid: r.URL.Query().Get("rid"), body: string(body)})
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []hit {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]hit(nil), hits...)
+	}
+}
+
+func TestPingSuccess(t *testing.T) {
+	srv, hits := monitor(t, nil)
+	job := &config.Job{Name: "web", Ping: &config.Ping{URL: srv.URL + "/abc/"}}
+
+	p := New(job, "run-1")
+	p.Start()
+	p.Finish(true, "")
+
+	got := hits()
+	if len(got) != 2 || got[0].path != "/abc/start" || got[1].path != "/abc" {
+		t.Fatalf("hits = %+v, want start then success", got)
+	}
+	for _, h := range got {
+		if h.rid != "run-1" {
+			t.Errorf("rid = %q", h.rid)
+		}
+	}
+}
+
+func TestPingFail(t *testing.T) {
+	srv, hits := monitor(t, nil)
+	job := &config.Job{Name: "web", Ping: &config.Ping{
+		Fail: srv.URL + "/custom-fail?token=x",
+	}}
+
+	p := New(job, "run-2")
+	p.Start()
+	p.Finish(false, "rsync exited with code 23")
+
+	got := hits()
+	if len(got) != 1 || got[0].path != "/custom-fail" {
+		t.Fatalf("hits = %+v, want only the explicit fail ping", got)
+	}
+	if !strings.Contains(got[0].body, "run-2") || !strings.Contains(got[0].body, "rsync exited with code 23") {
+		t.Errorf("fail body = %q", got[0].body)
+	}
+}
+
+func TestPingTimeoutDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	srv, _ := monitor(t, func() { <-release })
+	defer close(release)
+
+	job := &config.Job{Name: "web", Ping: &config.Ping{URL: srv.URL, Timeout: 50 * time.Millisecond}}
+	p := New(job, NewRunID())
+
+	begin := time.Now()
+	p.Start()
+	if time.Since(begin) > 20*time.Millisecond {
+		t.Error("Start blocked on the ping")
+	}
+	p.Finish(true, "")
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("Finish took %v with a 50ms ping timeout", elapsed)
+	}
+}
+
+func TestNilPinger(t *testing.T) {
+	p := New(&config.Job{Name: "web"}, "run")
+	if p != nil {
+		t.Fatal("expected nil pinger without ping settings")
+	}
+	p.Start()
+	p.Finish(false, "ignored")
+}
+
+func TestNewRunID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	a, b := NewRunID(), NewRunID()
+	if !uuid.MatchString(a) || a == b {
+		t.Errorf("NewRunID() = %q, %q", a, b)
+	}
+}
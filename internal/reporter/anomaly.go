@@ -0,0 +1,106 @@
+package reporter
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+const (
+	// baselineRuns is how many recent runs the baseline is taken from.
+	baselineRuns = 10
+	// baselineMinRuns is how many runs a job needs before runs are judged
+	// against its baseline.
+	baselineMinRuns = 5
+)
+
+// Baseline is a job's usual size: the median totals of its recent runs.
+type Baseline struct {
+	Runs       int
+	BytesTotal int64
+	FilesTotal int64
+}
+
+// BaselineFor computes the baseline from a job's history, newest first.
+// Failed and dry runs are left out, since their totals are partial. It
+// reports false until the job has baselineMinRuns usable runs.
+func BaselineFor(history []config.RunRecord) (Baseline, bool) {
+	var bytes, files []int64
+	for _, r := range history {
+		if r.DryRun || r.Status() == config.OutcomeFailure || r.FilesTotal == 0 {
+			continue
+		}
+		bytes = append(bytes, r.BytesTotal)
+		files = append(files, int64(r.FilesTotal))
+		if len(bytes) == baselineRuns {
+			break
+		}
+	}
+	if len(bytes) < baselineMinRuns {
+		return Baseline{}, false
+	}
+	return Baseline{Runs: len(bytes), BytesTotal: median(bytes), FilesTotal: median(files)}, true
+}
+
+func median(values []int64) int64 {
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// DetectAnomalies compares a run's totals with the baseline of the job's
+// earlier runs, newest first. Failed and dry runs are not judged.
+func DetectAnomalies(job *config.Job, record config.RunRecord, history []config.RunRecord) []config.SizeAnomaly {
+	if !job.Anomaly.Enabled() || record.DryRun || record.Status() == config.OutcomeFailure {
+		return nil
+	}
+	base, ok := BaselineFor(history)
+	if !ok {
+		return nil
+	}
+
+	drop, spike := job.Anomaly.Thresholds()
+	var anomalies []config.SizeAnomaly
+	for _, m := range []struct {
+		metric          string
+		value, baseline int64
+	}{
+		{"bytes_total", record.BytesTotal, base.BytesTotal},
+		{"files_total", int64(record.FilesTotal), base.FilesTotal},
+	} {
+		if m.baseline == 0 {
+			continue
+		}
+		change := float64(m.value-m.baseline) / float64(m.baseline) * 100
+		if change <= -drop || change >= spike {
+			anomalies = append(anomalies, config.SizeAnomaly{
+				Metric:   m.metric,
+				Value:    m.value,
+				Baseline: m.baseline,
+				Change:   change,
+			})
+		}
+	}
+	return anomalies
+}
+
+// FlagAnomalies marks record with any size anomalies against the job's
+// history. Call it before the record is appended.
+func (s *Store) FlagAnomalies(job *config.Job, record *config.RunRecord) {
+	history := s.GetJobRecords(job.Name, baselineRuns*2)
+	record.Anomalies = DetectAnomalies(job, *record, history)
+	for _, a := range record.Anomalies {
+		slog.Warn("backup size anomaly",
+			"job", job.Name,
+			"metric", a.Metric,
+			"value", a.Value,
+			"baseline", a.Baseline,
+			"change_pct", int(a.Change),
+		)
+	}
+}
@@ -0,0 +1,78 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+func sized(bytes int64, files int, outcome string) config.RunRecord {
+	return config.RunRecord{JobName: "home", Success: outcome != config.OutcomeFailure, Outcome: outcome, BytesTotal: bytes, FilesTotal: files}
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	// Newest first: a steady 10 GB / 1000 files, with a failed partial run
+	// that must not drag the baseline down.
+	var history []config.RunRecord
+	for i := 0; i < 8; i++ {
+		history = append(history, sized(10<<30+int64(i)<<20, 1000+i, config.OutcomeSuccess))
+	}
+	history = append([]config.RunRecord{sized(1<<20, 3, config.OutcomeFailure)}, history...)
+
+	job := &config.Job{Name: "home"}
+	tests := []struct {
+		name    string
+		record  config.RunRecord
+		metrics []string
+	}{
+		{"normal", sized(10<<30, 1010, config.OutcomeSuccess), nil},
+		{"source missing", sized(2<<30, 200, config.OutcomeSuccess), []string{"bytes_total", "files_total"}},
+		{"vm image dropped in", sized(40<<30, 1001, config.OutcomeWarning), []string{"bytes_total"}},
+		{"failed runs are not judged", sized(1, 1, config.OutcomeFailure), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectAnomalies(job, tt.record, history)
+			if len(got) != len(tt.metrics) {
+				t.Fatalf("anomalies = %+v, want %v", got, tt.metrics)
+			}
+			for i, a := range got {
+				if a.Metric != tt.metrics[i] {
+					t.Errorf("anomaly %d = %+v, want %s", i, a, tt.metrics[i])
+				}
+			}
+		})
+	}
+
+	if got := DetectAnomalies(job, sized(2<<30, 200, config.OutcomeSuccess), history[:4]); got != nil {
+		t.Errorf("flagged with too little history: %+v", got)
+	}
+	loose := &config.Job{Anomaly: &config.Anomaly{Drop: 90}}
+	if got := DetectAnomalies(loose, sized(2<<30, 200, config.OutcomeSuccess), history); got != nil {
+		t.Errorf("drop threshold ignored: %+v", got)
+	}
+	off := &config.Job{Anomaly: &config.Anomaly{Disabled: true}}
+	if got := DetectAnomalies(off, sized(40<<30, 1000, config.OutcomeSuccess), history); got != nil {
+		t.Errorf("disabled detection flagged %+v", got)
+	}
+}
+
+func TestFlagAnomaliesMarksHistory(t *testing.T) {
+	s := NewStoreAt(t.TempDir())
+	job := &config.Job{Name: "home"}
+	for i := 0; i < 6; i++ {
+		r := record("home", i)
+		r.Success, r.BytesTotal, r.FilesTotal = true, 10<<30, 1000
+		s.Append(r)
+	}
+
+	r := record("home", 6)
+	r.Success, r.BytesTotal, r.FilesTotal = true, 1<<30, 1000
+	s.FlagAnomalies(job, &r)
+	s.Append(r)
+
+	latest := s.GetJobRecords("home", 1)[0]
+	if len(latest.Anomalies) != 1 || latest.Anomalies[0].Change != -90 {
+		t.Errorf("stored anomalies = %+v", latest.Anomalies)
+	}
+}
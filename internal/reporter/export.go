@@ -0,0 +1,229 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// csvHeader lists the columns written by WriteCSV, in order.
+var csvHeader = []string{
+	"job_name", "started_at", "completed_at", "success",
+	"files_total", "files_transferred", "bytes_total", "bytes_transferred",
+	"dry_run", "trigger", "abort_reason", "errors",
+	"run_id", "hostname", "keeper_version", "rsync_version", "exit_code",
+	"outcome", "warnings",
+}
+
+// csvErrorSep joins a record's errors into a single CSV cell.
+const csvErrorSep = "; "
+
+// WriteCSV writes records as CSV with a header row. Times are RFC 3339.
+// Per-source breakdowns are only kept by the JSON format.
+func WriteCSV(w io.Writer, records []config.RunRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := []string{
+			r.JobName,
+			r.StartedAt.Format(time.RFC3339),
+			r.CompletedAt.Format(time.RFC3339),
+			strconv.FormatBool(r.Success),
+			strconv.Itoa(r.FilesTotal),
+			strconv.Itoa(r.FilesTransferred),
+			strconv.FormatInt(r.BytesTotal, 10),
+			strconv.FormatInt(r.BytesTransferred, 10),
+			strconv.FormatBool(r.DryRun),
+			r.Trigger,
+			r.AbortReason,
+			strings.Join(r.Errors, csvErrorSep),
+			r.RunID,
+			r.Hostname,
+			r.KeeperVersion,
+			r.RsyncVersion,
+			strconv.Itoa(r.ExitCode),
+			r.Status(),
+			strings.Join(r.Warnings, csvErrorSep),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads records written by WriteCSV. Columns are matched by header
+// name, so extra or reordered columns from a spreadsheet are fine.
+func ReadCSV(r io.Reader) ([]config.RunRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"job_name", "started_at", "completed_at", "success"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	var records []config.RunRecord
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec, err := parseCSVRow(row, col)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+func parseCSVRow(row []string, col map[string]int) (config.RunRecord, error) {
+	field := func(name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var rec config.RunRecord
+	var err error
+	rec.JobName = field("job_name")
+	if rec.JobName == "" {
+		return rec, fmt.Errorf("job_name is empty")
+	}
+	if rec.StartedAt, err = time.Parse(time.RFC3339, field("started_at")); err != nil {
+		return rec, fmt.Errorf("started_at: %w", err)
+	}
+	if rec.CompletedAt, err = time.Parse(time.RFC3339, field("completed_at")); err != nil {
+		return rec, fmt.Errorf("completed_at: %w", err)
+	}
+	if rec.Success, err = strconv.ParseBool(field("success")); err != nil {
+		return rec, fmt.Errorf("success: %w", err)
+	}
+	if v := field("dry_run"); v != "" {
+		if rec.DryRun, err = strconv.ParseBool(v); err != nil {
+			return rec, fmt.Errorf("dry_run: %w", err)
+		}
+	}
+
+	for name, dst := range map[string]*int{"files_total": &rec.FilesTotal, "files_transferred": &rec.FilesTransferred} {
+		if v := field(name); v != "" {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				return rec, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	for name, dst := range map[string]*int64{"bytes_total": &rec.BytesTotal, "bytes_transferred": &rec.BytesTransferred} {
+		if v := field(name); v != "" {
+			if *dst, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return rec, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
+	if v := field("exit_code"); v != "" {
+		if rec.ExitCode, err = strconv.Atoi(v); err != nil {
+			return rec, fmt.Errorf("exit_code: %w", err)
+		}
+	}
+
+	if v := field("warnings"); v != "" {
+		rec.Warnings = strings.Split(v, csvErrorSep)
+	}
+	rec.Outcome = field("outcome")
+	rec.RunID = field("run_id")
+	rec.Hostname = field("hostname")
+	rec.KeeperVersion = field("keeper_version")
+	rec.RsyncVersion = field("rsync_version")
+	rec.Trigger = field("trigger")
+	rec.AbortReason = field("abort_reason")
+	if v := field("errors"); v != "" {
+		rec.Errors = strings.Split(v, csvErrorSep)
+	}
+	return rec, nil
+}
+
+// WriteJSON writes records as an indented JSON array.
+func WriteJSON(w io.Writer, records []config.RunRecord) error {
+	if records == nil {
+		records = []config.RunRecord{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// ReadJSON reads records from a JSON array, or from JSONL as stored in the
+// history segments.
+func ReadJSON(r io.Reader) ([]config.RunRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []config.RunRecord
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, err
+		}
+		return records, nil
+	}
+
+	records, bad, err := scanRecords(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(bad) > 0 {
+		return nil, fmt.Errorf("line %d: %s", bad[0].Number, describeBadLine(bad[0].Data))
+	}
+	return records, nil
+}
+
+// WriteRollupsCSV writes daily roll-ups as CSV with a header row.
+func WriteRollupsCSV(w io.Writer, rollups []DailyRollup) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"job_name", "date", "runs", "successes", "warnings", "failures", "files_transferred", "bytes_transferred", "duration_seconds"}); err != nil {
+		return err
+	}
+	for _, r := range rollups {
+		row := []string{
+			r.JobName,
+			r.Date,
+			strconv.Itoa(r.Runs),
+			strconv.Itoa(r.Successes),
+			strconv.Itoa(r.Warnings),
+			strconv.Itoa(r.Failures),
+			strconv.FormatInt(r.FilesTransferred, 10),
+			strconv.FormatInt(r.BytesTransferred, 10),
+			strconv.FormatFloat(r.DurationSeconds, 'f', 0, 64),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
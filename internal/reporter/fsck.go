@@ -0,0 +1,164 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// corruptExt is appended to a segment's name to hold lines removed by a repair.
+const corruptExt = ".corrupt"
+
+// Issue is one problem found by Fsck.
+type Issue struct {
+	Path    string
+	Line    int
+	Problem string
+}
+
+// FsckReport summarises a history check.
+type FsckReport struct {
+	Segments int
+	Records  int
+	Issues   []Issue
+	Repaired bool
+}
+
+// Fsck checks every history segment for lines that aren't valid records,
+// segments missing their final newline, and leftovers of an interrupted
+// migration or compaction. With repair set it rewrites damaged segments
+// with only their valid records, saving removed lines next to the segment
+// with a .corrupt suffix. It moves history an interrupted compaction left
+// aside back into place and deletes the other leftovers.
+func (s *Store) Fsck(repair bool) (FsckReport, error) {
+	s.ready()
+
+	var report FsckReport
+
+	// Hold the writer lock so an append in flight isn't mistaken for a torn
+	// line, and nothing is written mid-repair.
+	unlock, err := s.lock()
+	if err != nil {
+		return report, err
+	}
+	defer unlock()
+
+	migrating, _ := filepath.Glob(filepath.Join(s.dir, historyDir+".migrating-*"))
+	for _, dir := range migrating {
+		report.Issues = append(report.Issues, Issue{Path: dir, Problem: "leftover from an interrupted migration"})
+		if repair {
+			if err := os.RemoveAll(dir); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	for _, escaped := range s.interruptedRewrites() {
+		dir := filepath.Join(s.root(), escaped)
+		problem := "leftover from an interrupted compaction"
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			problem = "history left aside by an interrupted compaction"
+		}
+		report.Issues = append(report.Issues, Issue{Path: dir, Problem: problem})
+		if repair {
+			if err := s.recoverRewrite(escaped); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	jobs, err := os.ReadDir(s.root())
+	if err != nil && !os.IsNotExist(err) {
+		return report, err
+	}
+	for _, job := range jobs {
+		if !job.IsDir() {
+			continue
+		}
+		dir := filepath.Join(s.root(), job.Name())
+		segments, err := listSegments(dir)
+		if err != nil {
+			return report, err
+		}
+		for _, seq := range segments {
+			if err := checkSegment(segmentPath(dir, seq), repair, &report); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	report.Repaired = repair && len(report.Issues) > 0
+	return report, nil
+}
+
+func checkSegment(path string, repair bool, report *FsckReport) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	report.Segments++
+
+	records, bad, err := scanRecords(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	report.Records += len(records)
+
+	torn := len(data) > 0 && data[len(data)-1] != '\n'
+	for _, b := range bad {
+		report.Issues = append(report.Issues, Issue{Path: path, Line: b.Number, Problem: describeBadLine(b.Data)})
+	}
+	if torn && len(bad) == 0 {
+		report.Issues = append(report.Issues, Issue{Path: path, Problem: "missing final newline"})
+	}
+
+	if !repair || (len(bad) == 0 && !torn) {
+		return nil
+	}
+
+	if len(bad) > 0 {
+		f, err := os.OpenFile(path+corruptExt, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		// Only drop the bad lines from the segment once they are safely
+		// kept aside.
+		for _, b := range bad {
+			if _, err := f.Write(append(bytes.TrimRight(b.Data, "\n"), '\n')); err != nil {
+				f.Close()
+				return fmt.Errorf("saving corrupt lines of %s: %w", path, err)
+			}
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("saving corrupt lines of %s: %w", path, err)
+		}
+	}
+
+	var clean bytes.Buffer
+	enc := json.NewEncoder(&clean)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, clean.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func describeBadLine(line []byte) string {
+	text := strings.TrimSpace(string(line))
+	if !strings.HasSuffix(string(line), "\n") {
+		return "partial record (torn write)"
+	}
+	if len(text) > 40 {
+		text = text[:40] + "..."
+	}
+	return fmt.Sprintf("invalid record: %q", text)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package reporter
+
+// lockFile is a no-op where flock is unavailable; appends still go out in a
+// single write, which keeps lines whole on most filesystems.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
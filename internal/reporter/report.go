@@ -0,0 +1,227 @@
+package reporter
+
+import (
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/klederson/keeper/internal/backend"
+	"github.com/klederson/keeper/internal/config"
+)
+
+// topCausesLimit caps how many error causes a report lists.
+const topCausesLimit = 10
+
+// Report summarises every job's runs over a period, for reviews.
+type Report struct {
+	Hostname  string      `json:"hostname"`
+	Since     time.Time   `json:"since"`
+	Until     time.Time   `json:"until"`
+	Stats     Stats       `json:"stats"`
+	Jobs      []JobReport `json:"jobs"`
+	Causes    []Cause     `json:"causes"`
+	Generated time.Time   `json:"generated"`
+}
+
+// JobReport is one job's part of a report. Volume has one entry per day of
+// the period, oldest first.
+type JobReport struct {
+	Name          string        `json:"name"`
+	Stats         Stats         `json:"stats"`
+	DurationP50   time.Duration `json:"duration_p50"`
+	DurationP95   time.Duration `json:"duration_p95"`
+	DurationMax   time.Duration `json:"duration_max"`
+	Volume        []DayVolume   `json:"volume"`
+	VolumeTrend   float64       `json:"volume_trend"`
+	LongestStreak Streak        `json:"longest_failure_streak"`
+	CurrentStreak int           `json:"current_failure_streak"`
+}
+
+// DayVolume is the data a job transferred on one day.
+type DayVolume struct {
+	Date  string `json:"date"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Streak is a run of consecutive failed runs.
+type Streak struct {
+	Runs  int       `json:"runs"`
+	Start time.Time `json:"start,omitzero"`
+	End   time.Time `json:"end,omitzero"`
+}
+
+// Cause counts the failed or warning runs that hit one kind of error.
+type Cause struct {
+	Kind  string   `json:"kind"`
+	Label string   `json:"label"`
+	Runs  int      `json:"runs"`
+	Jobs  []string `json:"jobs"`
+}
+
+// BuildReport summarises the runs of jobs that started in [since, until).
+func BuildReport(jobs []string, records []config.RunRecord, since, until time.Time) Report {
+	hostname, _ := os.Hostname()
+	rep := Report{
+		Hostname:  hostname,
+		Since:     since,
+		Until:     until,
+		Generated: time.Now(),
+	}
+
+	byJob := make(map[string][]config.RunRecord)
+	for _, r := range records {
+		if r.DryRun || r.StartedAt.Before(since) || !r.StartedAt.Before(until) {
+			continue
+		}
+		byJob[r.JobName] = append(byJob[r.JobName], r)
+	}
+
+	var counted []config.RunRecord
+	for _, name := range jobs {
+		runs := byJob[name]
+		sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.Before(runs[j].StartedAt) })
+		counted = append(counted, runs...)
+		rep.Jobs = append(rep.Jobs, buildJobReport(name, runs, since, until))
+	}
+	rep.Stats = CalculateStats(counted, since)
+	rep.Causes = topCauses(counted)
+	return rep
+}
+
+// buildJobReport summarises one job's runs, oldest first.
+func buildJobReport(name string, runs []config.RunRecord, since, until time.Time) JobReport {
+	js := JobStatsFor(name, runs, since)
+	jr := JobReport{
+		Name:          name,
+		Stats:         js.Stats,
+		DurationP50:   js.DurationP50,
+		DurationP95:   js.DurationP95,
+		DurationMax:   js.DurationMax,
+		LongestStreak: js.LongestStreak,
+		CurrentStreak: js.CurrentStreak,
+	}
+	jr.Volume = dailyVolume(runs, since, until)
+	jr.VolumeTrend = volumeTrend(jr.Volume)
+	return jr
+}
+
+// percentile returns the nearest-rank p-th percentile of durations.
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
+
+// dailyVolume buckets runs by calendar day, in since's time zone, from the
+// day since falls on through the day of the period's last instant.
+func dailyVolume(runs []config.RunRecord, since, until time.Time) []DayVolume {
+	loc := since.Location()
+	first := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, loc)
+	last := until.Add(-time.Nanosecond).In(loc)
+
+	var days []DayVolume
+	index := make(map[string]int)
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		index[date] = len(days)
+		days = append(days, DayVolume{Date: date})
+	}
+	for _, r := range runs {
+		if i, ok := index[r.StartedAt.In(loc).Format("2006-01-02")]; ok {
+			days[i].Bytes += r.BytesTransferred
+		}
+	}
+	return days
+}
+
+// volumeTrend compares the daily volume of the second half of the period
+// with the first, as a percentage change. It is 0 when the first half moved
+// no data, since there is nothing to compare against.
+func volumeTrend(days []DayVolume) float64 {
+	half := len(days) / 2
+	if half == 0 {
+		return 0
+	}
+	var first, second int64
+	for i, d := range days {
+		if i < half {
+			first += d.Bytes
+		} else if i >= len(days)-half {
+			second += d.Bytes
+		}
+	}
+	if first == 0 {
+		return 0
+	}
+	return float64(second-first) / float64(first) * 100
+}
+
+// failureStreaks returns the longest run of consecutive failures and the
+// number of failures since the last run that didn't fail.
+func failureStreaks(runs []config.RunRecord) (longest Streak, current int) {
+	var streak Streak
+	for _, r := range runs {
+		if r.Status() != config.OutcomeFailure {
+			streak = Streak{}
+			continue
+		}
+		if streak.Runs == 0 {
+			streak.Start = r.StartedAt
+		}
+		streak.Runs++
+		streak.End = r.CompletedAt
+		if streak.Runs > longest.Runs {
+			longest = streak
+		}
+	}
+	return longest, streak.Runs
+}
+
+// topCauses counts failed and warning runs by error kind. Runs with no
+// recognised error are counted by abort reason, or as unclassified.
+func topCauses(runs []config.RunRecord) []Cause {
+	var causes []Cause
+	index := make(map[string]int)
+	add := func(kind, label, job string) {
+		i, ok := index[kind]
+		if !ok {
+			i = len(causes)
+			index[kind] = i
+			causes = append(causes, Cause{Kind: kind, Label: label})
+		}
+		causes[i].Runs++
+		if !slices.Contains(causes[i].Jobs, job) {
+			causes[i].Jobs = append(causes[i].Jobs, job)
+		}
+	}
+
+	for _, r := range runs {
+		if r.Status() == config.OutcomeSuccess {
+			continue
+		}
+		switch {
+		case len(r.TypedErrors) > 0:
+			for _, e := range r.TypedErrors {
+				add(e.Kind, backend.ErrorLabel(e.Kind), r.JobName)
+			}
+		case r.AbortReason != "":
+			add(r.AbortReason, r.AbortReason, r.JobName)
+		default:
+			add("unclassified", "unclassified", r.JobName)
+		}
+	}
+
+	sort.SliceStable(causes, func(i, j int) bool { return causes[i].Runs > causes[j].Runs })
+	if len(causes) > topCausesLimit {
+		causes = causes[:topCausesLimit]
+	}
+	return causes
+}
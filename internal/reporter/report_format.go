@@ -0,0 +1,217 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Report formats accepted by WriteReport.
+const (
+	ReportHTML     = "html"
+	ReportMarkdown = "md"
+	ReportJSON     = "json"
+)
+
+// WriteReport renders the report in the given format.
+func WriteReport(w io.Writer, rep Report, format string) error {
+	switch format {
+	case ReportJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rep)
+	case ReportMarkdown:
+		return reportMarkdown.Execute(w, rep)
+	case ReportHTML:
+		return reportHTML.Execute(w, rep)
+	default:
+		return fmt.Errorf("unknown report format %q: use html, md or json", format)
+	}
+}
+
+var reportFuncs = template.FuncMap{
+	"bytes": FormatBytes,
+	"pct":   func(f float64) string { return fmt.Sprintf("%.1f%%", f) },
+	"date":  func(t time.Time) string { return t.Format("2006-01-02") },
+	"dur":   func(d time.Duration) string { return d.Round(time.Second).String() },
+	"trend": formatTrend,
+	"join":  strings.Join,
+	"total": func(days []DayVolume) int64 {
+		var n int64
+		for _, d := range days {
+			n += d.Bytes
+		}
+		return n
+	},
+}
+
+func formatTrend(pct float64) string {
+	switch {
+	case pct > 0:
+		return fmt.Sprintf("▲ %.0f%%", pct)
+	case pct < 0:
+		return fmt.Sprintf("▼ %.0f%%", -pct)
+	default:
+		return "—"
+	}
+}
+
+// FormatBytes renders a byte count with a binary unit, e.g. "1.5 MB".
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// volumeChart draws daily volume as an inline SVG bar chart, so the HTML
+// report needs no scripts or external files.
+func volumeChart(days []DayVolume) htmltemplate.HTML {
+	const width, height = 360.0, 60.0
+	var peak int64
+	for _, d := range days {
+		peak = max(peak, d.Bytes)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" role="img">`, width, height, width, height)
+	fmt.Fprintf(&b, `<line x1="0" y1="%.1f" x2="%.0f" y2="%.1f" stroke="#ccc"/>`, height-0.5, width, height-0.5)
+	if len(days) > 0 && peak > 0 {
+		slot := width / float64(len(days))
+		for i, d := range days {
+			h := float64(d.Bytes) / float64(peak) * (height - 2)
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#4a90d9"><title>%s: %s</title></rect>`,
+				float64(i)*slot+0.5, height-h, max(slot-1, 1), h, d.Date, FormatBytes(d.Bytes))
+		}
+	}
+	b.WriteString(`</svg>`)
+	return htmltemplate.HTML(b.String())
+}
+
+// rateBar draws a success rate as a horizontal bar split into successful,
+// warning and failed runs.
+func rateBar(s Stats) htmltemplate.HTML {
+	const width, height = 160.0, 12.0
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" role="img">`, width, height)
+	if s.TotalRuns == 0 {
+		fmt.Fprintf(&b, `<rect width="%.0f" height="%.0f" fill="#eee"/>`, width, height)
+	} else {
+		x := 0.0
+		for _, part := range []struct {
+			n     int
+			color string
+		}{{s.SuccessCount, "#3fa34d"}, {s.WarningCount, "#e0a526"}, {s.FailCount, "#d9534f"}} {
+			w := float64(part.n) / float64(s.TotalRuns) * width
+			fmt.Fprintf(&b, `<rect x="%.1f" width="%.1f" height="%.0f" fill="%s"/>`, x, w, height, part.color)
+			x += w
+		}
+	}
+	b.WriteString(`</svg>`)
+	return htmltemplate.HTML(b.String())
+}
+
+var reportMarkdown = template.Must(template.New("report").Funcs(reportFuncs).Parse(`# Backup report for {{ .Hostname }}
+
+{{ date .Since }} to {{ date .Until }}
+
+| Runs | Success rate | Warnings | Failures | Transferred | Avg duration |
+|-----:|-------------:|---------:|---------:|------------:|-------------:|
+| {{ .Stats.TotalRuns }} | {{ pct .Stats.SuccessRate }} | {{ .Stats.WarningCount }} | {{ .Stats.FailCount }} | {{ bytes .Stats.TotalBytes }} | {{ dur .Stats.AvgDuration }} |
+
+## Jobs
+
+| Job | Runs | Success rate | Warnings | p50 | p95 | Max | Transferred | Trend | Longest failure streak | Failing now |
+|-----|-----:|-------------:|---------:|----:|----:|----:|------------:|------:|-----------------------:|------------:|
+{{- range .Jobs }}
+| {{ .Name }} | {{ .Stats.TotalRuns }} | {{ pct .Stats.SuccessRate }} | {{ .Stats.WarningCount }} | {{ dur .DurationP50 }} | {{ dur .DurationP95 }} | {{ dur .DurationMax }} | {{ bytes (total .Volume) }} | {{ trend .VolumeTrend }} | {{ if .LongestStreak.Runs }}{{ .LongestStreak.Runs }} ({{ date .LongestStreak.Start }} – {{ date .LongestStreak.End }}){{ else }}—{{ end }} | {{ if .CurrentStreak }}{{ .CurrentStreak }}{{ else }}—{{ end }} |
+{{- end }}
+{{- if .Causes }}
+
+## Top error causes
+
+| Cause | Runs | Jobs |
+|-------|-----:|------|
+{{- range .Causes }}
+| {{ .Label }} | {{ .Runs }} | {{ join .Jobs ", " }} |
+{{- end }}
+{{- end }}
+
+_Generated {{ .Generated.Format "2006-01-02 15:04" }} by keeper._
+`))
+
+var reportHTML = htmltemplate.Must(htmltemplate.New("report").Funcs(htmltemplate.FuncMap(reportFuncs)).Funcs(htmltemplate.FuncMap{
+	"volumeChart": volumeChart,
+	"rateBar":     rateBar,
+}).Parse(`<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8">
+<title>Backup report for {{ .Hostname }}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; margin: 2em auto; max-width: 1000px; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { padding: 6px 10px; border-bottom: 1px solid #ddd; text-align: left; vertical-align: middle; }
+td.num, th.num { text-align: right; }
+.muted { color: #888; }
+.card { display: inline-block; margin: 0 2em 1em 0; }
+.card b { display: block; font-size: 1.6em; }
+.fail { color: #d9534f; }
+</style></head>
+<body>
+<h1>Backup report for {{ .Hostname }}</h1>
+<p class="muted">{{ date .Since }} to {{ date .Until }}</p>
+
+<div>
+<span class="card"><b>{{ .Stats.TotalRuns }}</b>runs</span>
+<span class="card"><b>{{ pct .Stats.SuccessRate }}</b>success rate</span>
+<span class="card"><b>{{ .Stats.WarningCount }}</b>warnings</span>
+<span class="card"><b>{{ .Stats.FailCount }}</b>failures</span>
+<span class="card"><b>{{ bytes .Stats.TotalBytes }}</b>transferred</span>
+</div>
+
+<h2>Jobs</h2>
+<table>
+<tr><th>Job</th><th class="num">Runs</th><th>Success rate</th><th class="num">p50</th><th class="num">p95</th><th class="num">Max</th><th>Failure streaks</th></tr>
+{{- range .Jobs }}
+<tr>
+<td>{{ .Name }}</td>
+<td class="num">{{ .Stats.TotalRuns }}</td>
+<td>{{ rateBar .Stats }} {{ pct .Stats.SuccessRate }}</td>
+<td class="num">{{ dur .DurationP50 }}</td>
+<td class="num">{{ dur .DurationP95 }}</td>
+<td class="num">{{ dur .DurationMax }}</td>
+<td>{{ if .LongestStreak.Runs }}longest {{ .LongestStreak.Runs }} ({{ date .LongestStreak.Start }} – {{ date .LongestStreak.End }}){{ else }}<span class="muted">none</span>{{ end }}{{ if .CurrentStreak }}<br><span class="fail">failing now: {{ .CurrentStreak }} runs</span>{{ end }}</td>
+</tr>
+{{- end }}
+</table>
+
+<h2>Data volume</h2>
+<table>
+<tr><th>Job</th><th>Per day</th><th class="num">Total</th><th class="num">Trend</th></tr>
+{{- range .Jobs }}
+<tr><td>{{ .Name }}</td><td>{{ volumeChart .Volume }}</td><td class="num">{{ bytes (total .Volume) }}</td><td class="num">{{ trend .VolumeTrend }}</td></tr>
+{{- end }}
+</table>
+{{- if .Causes }}
+
+<h2>Top error causes</h2>
+<table>
+<tr><th>Cause</th><th class="num">Runs</th><th>Jobs</th></tr>
+{{- range .Causes }}
+<tr><td>{{ .Label }}</td><td class="num">{{ .Runs }}</td><td>{{ join .Jobs ", " }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+
+<p class="muted">Generated {{ .Generated.Format "2006-01-02 15:04" }} by keeper.</p>
+</body></html>
+`))
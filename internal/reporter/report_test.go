@@ -0,0 +1,113 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+func reportRecords() []config.RunRecord {
+	var records []config.RunRecord
+	for day := 0; day < 10; day++ {
+		start := epoch.AddDate(0, 0, day).Add(2 * time.Hour)
+		r := config.RunRecord{
+			JobName:          "web",
+			StartedAt:        start,
+			CompletedAt:      start.Add(time.Duration(day+1) * time.Minute),
+			Success:          true,
+			BytesTransferred: int64(day+1) << 20,
+		}
+		// Days 3-5 fail on permissions, day 9 times out.
+		if day >= 3 && day <= 5 {
+			r.Success = false
+			r.TypedErrors = []config.RunError{{Kind: config.ErrPermissionDenied, Count: 2, Paths: []string{"/srv/a"}}}
+		}
+		if day == 9 {
+			r.Success = false
+			r.AbortReason = config.AbortTimeout
+		}
+		records = append(records, r)
+	}
+	records = append(records, config.RunRecord{JobName: "gone", StartedAt: epoch, CompletedAt: epoch, Success: false})
+	return records
+}
+
+func TestBuildReport(t *testing.T) {
+	rep := BuildReport([]string{"web", "idle"}, reportRecords(), epoch, epoch.AddDate(0, 0, 10))
+
+	if rep.Stats.TotalRuns != 10 || rep.Stats.FailCount != 4 {
+		t.Errorf("overall stats = %+v", rep.Stats)
+	}
+	if len(rep.Jobs) != 2 {
+		t.Fatalf("jobs = %+v", rep.Jobs)
+	}
+
+	web := rep.Jobs[0]
+	if web.DurationP50 != 5*time.Minute || web.DurationP95 != 10*time.Minute || web.DurationMax != 10*time.Minute {
+		t.Errorf("durations p50 %v p95 %v max %v", web.DurationP50, web.DurationP95, web.DurationMax)
+	}
+	if len(web.Volume) != 10 || web.Volume[0].Bytes != 1<<20 || web.VolumeTrend <= 0 {
+		t.Errorf("volume = %v, trend %v", web.Volume, web.VolumeTrend)
+	}
+	if web.LongestStreak.Runs != 3 || !web.LongestStreak.Start.Equal(epoch.AddDate(0, 0, 3).Add(2*time.Hour)) || web.CurrentStreak != 1 {
+		t.Errorf("streaks = %+v, current %d", web.LongestStreak, web.CurrentStreak)
+	}
+
+	if len(rep.Causes) != 2 || rep.Causes[0].Kind != config.ErrPermissionDenied || rep.Causes[0].Runs != 3 || rep.Causes[1].Kind != config.AbortTimeout {
+		t.Errorf("causes = %+v", rep.Causes)
+	}
+	if idle := rep.Jobs[1]; idle.Stats.TotalRuns != 0 || idle.DurationP95 != 0 {
+		t.Errorf("idle job = %+v", idle)
+	}
+}
+
+func TestReportVolumeIncludesToday(t *testing.T) {
+	// A --since 30d report runs from and to the current time of day, so the
+	// period ends partway through today.
+	since := epoch.Add(15 * time.Hour)
+	until := epoch.AddDate(0, 0, 9).Add(15 * time.Hour)
+	rep := BuildReport([]string{"web"}, reportRecords(), since, until)
+
+	web := rep.Jobs[0]
+	if len(web.Volume) != 10 || web.Volume[9].Date != "2026-01-10" || web.Volume[9].Bytes != 10<<20 {
+		t.Fatalf("volume = %v", web.Volume)
+	}
+	var total int64
+	for _, d := range web.Volume {
+		total += d.Bytes
+	}
+	if total != web.Stats.TotalBytes {
+		t.Errorf("volume total = %d, stats total = %d", total, web.Stats.TotalBytes)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	rep := BuildReport([]string{"web"}, reportRecords(), epoch, epoch.AddDate(0, 0, 10))
+
+	var md, html, js bytes.Buffer
+	for format, buf := range map[string]*bytes.Buffer{ReportMarkdown: &md, ReportHTML: &html, ReportJSON: &js} {
+		if err := WriteReport(buf, rep, format); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+	}
+
+	if !strings.Contains(md.String(), "| web | 10 | 60.0% |") || !strings.Contains(md.String(), "| permission denied | 3 | web |") {
+		t.Errorf("markdown:\n%s", md.String())
+	}
+	if !strings.Contains(html.String(), "<svg") || strings.Contains(html.String(), "<script") || strings.Contains(html.String(), "http-equiv") {
+		t.Errorf("html is not a self-contained page with inline charts")
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil || decoded.Jobs[0].LongestStreak.Runs != 3 {
+		t.Errorf("json round trip: %v, %+v", err, decoded.Jobs)
+	}
+
+	if err := WriteReport(&md, rep, "pdf"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
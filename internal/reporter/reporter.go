@@ -1,12 +1,18 @@
 package reporter
 
 import (
+	"os"
+
 	"github.com/klederson/keeper/internal/backend"
 	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/version"
 )
 
 func ResultToRecord(jobName string, result *backend.Result, dryRun bool) config.RunRecord {
-	return config.RunRecord{
+	hostname, _ := os.Hostname()
+
+	record := config.RunRecord{
+		RunID:            result.RunID,
 		JobName:          jobName,
 		StartedAt:        result.StartedAt,
 		CompletedAt:      result.CompletedAt,
@@ -16,6 +22,28 @@ func ResultToRecord(jobName string, result *backend.Result, dryRun bool) config.
 		BytesTotal:       result.BytesTotal,
 		BytesTransferred: result.BytesTransferred,
 		Errors:           result.Errors,
+		Warnings:         result.Warnings,
+		TypedErrors:      result.TypedErrors,
+		Outcome:          result.Outcome(),
 		DryRun:           dryRun,
+		AbortReason:      result.AbortReason,
+		Hostname:         hostname,
+		KeeperVersion:    version.Version,
+		RsyncVersion:     result.RsyncVersion,
+		ExitCode:         result.ExitCode,
+	}
+	for _, src := range result.Sources {
+		record.Sources = append(record.Sources, config.SourceRecord{
+			Path:             src.Path,
+			FilesTotal:       src.FilesTotal,
+			FilesTransferred: src.FilesTransferred,
+			BytesTotal:       src.BytesTotal,
+			BytesTransferred: src.BytesTransferred,
+			Errors:           src.Errors,
+			Warnings:         src.Warnings,
+			TypedErrors:      src.TypedErrors,
+			ExitCode:         src.ExitCode,
+		})
 	}
+	return record
 }
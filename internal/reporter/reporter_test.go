@@ -0,0 +1,47 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/backend"
+	"github.com/klederson/keeper/internal/version"
+)
+
+func TestResultToRecordKeepsSources(t *testing.T) {
+	result := &backend.Result{
+		RunID:            "run-1",
+		StartedAt:        epoch,
+		CompletedAt:      epoch.Add(time.Minute),
+		FilesTransferred: 3,
+		Errors:           []string{"rsync exited with code 23: partial transfer due to error"},
+		ExitCode:         23,
+		RsyncVersion:     "3.2.7",
+		Sources: []backend.SourceResult{
+			{Path: "~/docs", FilesTransferred: 3},
+			{Path: "~/photos", Errors: []string{"rsync exited with code 23: partial transfer due to error"}, ExitCode: 23},
+		},
+	}
+
+	r := ResultToRecord("home", result, false)
+	if r.RunID != "run-1" || r.ExitCode != 23 || r.RsyncVersion != "3.2.7" || r.KeeperVersion != version.Version || r.Hostname == "" {
+		t.Errorf("record = %+v", r)
+	}
+	if len(r.Sources) != 2 || !r.Sources[0].Success() || r.Sources[1].Success() || r.Sources[1].ExitCode != 23 {
+		t.Errorf("sources = %+v", r.Sources)
+	}
+}
+
+func TestOldRecordsLoad(t *testing.T) {
+	old := `{"job_name":"home","started_at":"2025-03-01T02:00:00Z","completed_at":"2025-03-01T02:05:00Z","success":true,"files_total":10,"files_transferred":2,"bytes_total":100,"bytes_transferred":20,"dry_run":false}
+`
+	records := decodeRecords(strings.NewReader(old))
+	if len(records) != 1 {
+		t.Fatalf("decoded %d records", len(records))
+	}
+	r := records[0]
+	if r.JobName != "home" || !r.Success || r.RunID != "" || r.Sources != nil || r.ExitCode != 0 {
+		t.Errorf("record = %+v", r)
+	}
+}
@@ -0,0 +1,333 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+const rollupFile = "rollup.jsonl"
+
+// DailyRollup aggregates one day of a job's runs that retention removed.
+type DailyRollup struct {
+	JobName          string  `json:"job_name"`
+	Date             string  `json:"date"`
+	Runs             int     `json:"runs"`
+	Successes        int     `json:"successes"`
+	Warnings         int     `json:"warnings,omitempty"`
+	Failures         int     `json:"failures"`
+	FilesTransferred int64   `json:"files_transferred"`
+	BytesTransferred int64   `json:"bytes_transferred"`
+	DurationSeconds  float64 `json:"duration_seconds"`
+}
+
+// RetentionResult reports what retention did to one job's history.
+type RetentionResult struct {
+	Job      string
+	Removed  int
+	RolledUp int
+}
+
+// ApplyRetention trims every job's history to the policy's max age and max
+// record count, folding removed runs into daily roll-ups when the policy asks
+// for them. With PruneRemovedJobs, history of jobs not in jobs is deleted.
+func (s *Store) ApplyRetention(policy config.History, jobs []string, now time.Time) ([]RetentionResult, error) {
+	s.ready()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	configured := make(map[string]bool, len(jobs))
+	for _, name := range jobs {
+		configured[name] = true
+	}
+
+	var results []RetentionResult
+	for _, job := range s.Jobs() {
+		records, err := s.readJob(job)
+		if err != nil {
+			return results, err
+		}
+
+		if policy.PruneRemovedJobs && !configured[job] {
+			if err := os.RemoveAll(s.jobDir(job)); err != nil {
+				return results, err
+			}
+			results = append(results, RetentionResult{Job: job, Removed: len(records)})
+			continue
+		}
+
+		keep, drop := splitRetained(records, policy, now)
+		if len(drop) == 0 {
+			continue
+		}
+
+		rollups := s.Rollups(job)
+		result := RetentionResult{Job: job, Removed: len(drop)}
+		if policy.Rollup {
+			rollups = mergeRollups(rollups, drop)
+			result.RolledUp = len(drop)
+		}
+		if err := s.rewriteJob(job, keep, rollups); err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// splitRetained divides records, oldest first, into those the policy keeps
+// and those it removes.
+func splitRetained(records []config.RunRecord, policy config.History, now time.Time) (keep, drop []config.RunRecord) {
+	keep = records
+	if policy.MaxAge > 0 {
+		cutoff := now.Add(-policy.MaxAge)
+		i := sort.Search(len(keep), func(i int) bool { return !keep[i].CompletedAt.Before(cutoff) })
+		drop, keep = keep[:i:i], keep[i:]
+	}
+	if policy.MaxRecords > 0 && len(keep) > policy.MaxRecords {
+		n := len(keep) - policy.MaxRecords
+		drop, keep = append(drop, keep[:n]...), keep[n:]
+	}
+	return keep, drop
+}
+
+func mergeRollups(rollups []DailyRollup, records []config.RunRecord) []DailyRollup {
+	index := make(map[string]int, len(rollups))
+	for i, r := range rollups {
+		index[r.Date] = i
+	}
+
+	for _, r := range records {
+		if r.DryRun {
+			continue
+		}
+		date := r.StartedAt.Format("2006-01-02")
+		i, ok := index[date]
+		if !ok {
+			i = len(rollups)
+			index[date] = i
+			rollups = append(rollups, DailyRollup{JobName: r.JobName, Date: date})
+		}
+
+		day := &rollups[i]
+		day.Runs++
+		switch r.Status() {
+		case config.OutcomeSuccess:
+			day.Successes++
+		case config.OutcomeWarning:
+			day.Warnings++
+		default:
+			day.Failures++
+		}
+		day.FilesTransferred += int64(r.FilesTransferred)
+		day.BytesTransferred += r.BytesTransferred
+		day.DurationSeconds += r.CompletedAt.Sub(r.StartedAt).Seconds()
+	}
+
+	sort.Slice(rollups, func(i, j int) bool { return rollups[i].Date < rollups[j].Date })
+	return rollups
+}
+
+// Rollups returns the job's daily aggregates of removed runs, oldest first.
+func (s *Store) Rollups(jobName string) []DailyRollup {
+	data, err := os.ReadFile(filepath.Join(s.jobDir(jobName), rollupFile))
+	if err != nil {
+		return nil
+	}
+	var rollups []DailyRollup
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var r DailyRollup
+		if json.Unmarshal(line, &r) == nil {
+			rollups = append(rollups, r)
+		}
+	}
+	return rollups
+}
+
+// Import adds records to history, skipping any already present, and keeps
+// each job's history in completion order.
+func (s *Store) Import(records []config.RunRecord) (int, error) {
+	s.ready()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	byJob := make(map[string][]config.RunRecord)
+	for _, r := range records {
+		byJob[r.JobName] = append(byJob[r.JobName], r)
+	}
+
+	added := 0
+	for job, incoming := range byJob {
+		existing, err := s.readJob(job)
+		if err != nil {
+			return added, err
+		}
+
+		seen := make(map[string]bool, len(existing))
+		for _, r := range existing {
+			seen[recordKey(r)] = true
+		}
+		merged := existing
+		for _, r := range incoming {
+			if key := recordKey(r); !seen[key] {
+				seen[key] = true
+				merged = append(merged, r)
+				added++
+			}
+		}
+		if len(merged) == len(existing) {
+			continue
+		}
+
+		sort.SliceStable(merged, func(i, j int) bool { return merged[i].CompletedAt.Before(merged[j].CompletedAt) })
+		if err := s.rewriteJob(job, merged, s.Rollups(job)); err != nil {
+			return added, err
+		}
+	}
+	return added, nil
+}
+
+// recordKey identifies a run for de-duplication on import.
+func recordKey(r config.RunRecord) string {
+	return r.JobName + "\x00" + r.StartedAt.UTC().Format(time.RFC3339Nano) + "\x00" + r.CompletedAt.UTC().Format(time.RFC3339Nano)
+}
+
+// readJob returns all of the job's records in the order they were written.
+func (s *Store) readJob(jobName string) ([]config.RunRecord, error) {
+	dir := s.jobDir(jobName)
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	var records []config.RunRecord
+	for _, seq := range segments {
+		segment, err := readSegment(segmentPath(dir, seq))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, segment...)
+	}
+	return records, nil
+}
+
+// rewriteJob replaces a job's history. The new segments are built beside
+// the history directory and swapped in with renames; the caller must hold
+// the history lock.
+func (s *Store) rewriteJob(jobName string, records []config.RunRecord, rollups []DailyRollup) error {
+	dir := s.jobDir(jobName)
+	if err := s.recoverRewrite(escapeJob(jobName)); err != nil {
+		return err
+	}
+	if len(records) == 0 && len(rollups) == 0 {
+		return os.RemoveAll(dir)
+	}
+
+	if err := os.MkdirAll(s.root(), 0755); err != nil {
+		return err
+	}
+	tmp, old := s.rewritePaths(escapeJob(jobName))
+
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	if err := writeSegments(tmp, data.Bytes()); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+
+	if len(rollups) > 0 {
+		data.Reset()
+		for _, r := range rollups {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		if err := os.WriteFile(filepath.Join(tmp, rollupFile), data.Bytes(), 0644); err != nil {
+			os.RemoveAll(tmp)
+			return err
+		}
+	}
+
+	if err := os.Rename(dir, old); err != nil && !os.IsNotExist(err) {
+		os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		os.Rename(old, dir)
+		return err
+	}
+	return os.RemoveAll(old)
+}
+
+// rewritePaths returns where rewriteJob builds a job's new history and
+// where it parks the old one while swapping them.
+func (s *Store) rewritePaths(escaped string) (tmp, old string) {
+	return filepath.Join(s.dir, historyDir+".rewrite-"+escaped),
+		filepath.Join(s.dir, historyDir+".old-"+escaped)
+}
+
+// interruptedRewrites lists, by escaped job name, the jobs that a crash
+// left with rewrite leftovers.
+func (s *Store) interruptedRewrites() []string {
+	var escaped []string
+	for _, prefix := range []string{".rewrite-", ".old-"} {
+		matches, _ := filepath.Glob(filepath.Join(s.dir, historyDir+prefix+"*"))
+		for _, m := range matches {
+			name := strings.TrimPrefix(filepath.Base(m), historyDir+prefix)
+			if !slices.Contains(escaped, name) {
+				escaped = append(escaped, name)
+			}
+		}
+	}
+	return escaped
+}
+
+// recoverRewrite cleans up after an interrupted rewriteJob; the caller must
+// hold the history lock. A crash between its two renames leaves the job
+// without a directory, so the parked old history, or failing that the
+// finished new one, is moved back. Leftovers are deleted only once the
+// job's directory exists.
+func (s *Store) recoverRewrite(escaped string) error {
+	dir := filepath.Join(s.root(), escaped)
+	tmp, old := s.rewritePaths(escaped)
+
+	for _, p := range []string{old, tmp} {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			slog.Warn("restoring history left aside by an interrupted rewrite", "path", p)
+			if err := os.MkdirAll(s.root(), 0755); err != nil {
+				return err
+			}
+			if err := os.Rename(p, dir); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.RemoveAll(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
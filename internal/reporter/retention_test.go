@@ -0,0 +1,227 @@
+package reporter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+func TestApplyRetention(t *testing.T) {
+	s := NewStoreAt(t.TempDir())
+	for i := 0; i < 72; i++ {
+		s.Append(record("web", i))
+		s.Append(record("gone", i))
+	}
+	now := epoch.Add(72 * time.Hour)
+
+	policy := config.History{MaxAge: 48 * time.Hour, MaxRecords: 40, Rollup: true, PruneRemovedJobs: true}
+	results, err := s.ApplyRetention(policy, []string{"web"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %+v", results)
+	}
+
+	web := s.GetJobRecords("web", 0)
+	if len(web) != 40 || web[0].CompletedAt != record("web", 71).CompletedAt || web[39].CompletedAt != record("web", 32).CompletedAt {
+		t.Errorf("kept %d records, newest %v oldest %v", len(web), web[0].StartedAt, web[len(web)-1].StartedAt)
+	}
+	if recs := s.GetJobRecords("gone", 0); len(recs) != 0 {
+		t.Errorf("removed job still has %d records", len(recs))
+	}
+
+	rollups := s.Rollups("web")
+	if len(rollups) != 2 || rollups[0].Date != "2026-01-01" || rollups[0].Runs != 24 || rollups[1].Runs != 8 {
+		t.Fatalf("rollups = %+v", rollups)
+	}
+	if rollups[0].Successes+rollups[0].Failures != 24 || rollups[0].DurationSeconds != 24*60 {
+		t.Errorf("day one = %+v", rollups[0])
+	}
+
+	// A second pass with nothing to drop leaves history alone, and new
+	// roll-ups merge into existing days.
+	if results, _ := s.ApplyRetention(policy, []string{"web"}, now); len(results) != 0 {
+		t.Errorf("second pass results = %+v", results)
+	}
+	s.ApplyRetention(config.History{MaxRecords: 30, Rollup: true}, nil, now)
+	if rollups := s.Rollups("web"); len(rollups) != 2 || rollups[1].Runs != 18 {
+		t.Errorf("merged rollups = %+v", rollups)
+	}
+}
+
+func TestImportSkipsDuplicates(t *testing.T) {
+	s := NewStoreAt(t.TempDir())
+	for i := 0; i < 10; i += 2 {
+		s.Append(record("web", i))
+	}
+
+	var incoming []config.RunRecord
+	for i := 0; i < 10; i++ {
+		incoming = append(incoming, record("web", i))
+	}
+	incoming = append(incoming, record("db", 3))
+
+	added, err := s.Import(incoming)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 6 {
+		t.Errorf("added = %d, want 6", added)
+	}
+
+	web := s.GetJobRecords("web", 0)
+	if len(web) != 10 {
+		t.Fatalf("web has %d records", len(web))
+	}
+	for i, r := range web {
+		if r.StartedAt != record("web", 9-i).StartedAt {
+			t.Errorf("web[%d] started %v, history is out of order", i, r.StartedAt)
+		}
+	}
+
+	if added, _ := s.Import(incoming); added != 0 {
+		t.Errorf("re-import added %d", added)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	records := []config.RunRecord{record("web", 1), record("db, \"main\"", 2)}
+	records[1].Errors = []string{"rsync: link_stat failed", "exit code 23"}
+	records[1].Trigger = config.TriggerSchedule
+	records[1].RunID = "5f0c7a3e-1d2b-4c8e-9a61-0b7d2e4f9c13"
+	records[1].Hostname = "nas"
+	records[1].KeeperVersion = "v1.4.0"
+	records[1].RsyncVersion = "3.2.7"
+	records[1].ExitCode = 23
+	records[0].Warnings = []string{"file has vanished: /srv/tmp/x"}
+	records[0].Outcome = config.OutcomeWarning
+	records[1].Success = false
+	records[1].Outcome = config.OutcomeFailure
+	for i := range records {
+		records[i].StartedAt = records[i].StartedAt.Local()
+		records[i].CompletedAt = records[i].CompletedAt.Local()
+	}
+
+	var csvOut bytes.Buffer
+	if err := WriteCSV(&csvOut, records); err != nil {
+		t.Fatal(err)
+	}
+	fromCSV, err := ReadCSV(&csvOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var jsonOut bytes.Buffer
+	if err := WriteJSON(&jsonOut, records); err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := ReadJSON(&jsonOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, got := range map[string][]config.RunRecord{"csv": fromCSV, "json": fromJSON} {
+		if len(got) != len(records) {
+			t.Fatalf("%s: got %d records", name, len(got))
+		}
+		for i := range got {
+			if !got[i].StartedAt.Equal(records[i].StartedAt) || !got[i].CompletedAt.Equal(records[i].CompletedAt) {
+				t.Errorf("%s[%d] times = %v, %v", name, i, got[i].StartedAt, got[i].CompletedAt)
+			}
+			got[i].StartedAt, got[i].CompletedAt = records[i].StartedAt, records[i].CompletedAt
+			if !reflect.DeepEqual(got[i], records[i]) {
+				t.Errorf("%s[%d] = %+v, want %+v", name, i, got[i], records[i])
+			}
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteRollupsCSVReportsErrors(t *testing.T) {
+	rollups := []DailyRollup{{JobName: "web", Date: "2026-01-01", Runs: 3, Successes: 3}}
+	if err := WriteRollupsCSV(failingWriter{}, rollups); err == nil {
+		t.Error("expected the write error to be returned")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRollupsCSV(&buf, rollups); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("web,2026-01-01,3,3,0,0,0,0,0\n")) {
+		t.Errorf("rollups CSV:\n%s", buf.String())
+	}
+}
+
+// crashMidRewrite leaves web's history the way a crash between rewriteJob's
+// two renames would: the old history parked aside and the job dir missing.
+func crashMidRewrite(t *testing.T, s *Store, keepOld bool) {
+	t.Helper()
+	tmp, old := s.rewritePaths(escapeJob("web"))
+	if err := os.Rename(s.jobDir("web"), old); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !keepOld {
+		os.RemoveAll(tmp)
+		if err := os.Rename(old, tmp); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRecoverInterruptedRewrite(t *testing.T) {
+	s := NewStoreAt(t.TempDir())
+	for i := 0; i < 5; i++ {
+		s.Append(record("web", i))
+	}
+	tmp, old := s.rewritePaths(escapeJob("web"))
+
+	crashMidRewrite(t, s, true)
+	report, err := s.Fsck(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Problem != "history left aside by an interrupted compaction" {
+		t.Fatalf("Fsck() issues = %+v", report.Issues)
+	}
+	if _, err := os.Stat(old); err != nil {
+		t.Fatalf("Fsck without repair touched the parked history: %v", err)
+	}
+
+	if _, err := s.Fsck(true); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.GetJobRecords("web", 0)); n != 5 {
+		t.Errorf("after repair: got %d records, want 5", n)
+	}
+	for _, p := range []string{tmp, old} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s left behind: %v", p, err)
+		}
+	}
+
+	// With only the new history left, that is what comes back. A fresh
+	// store recovers it before retention gets to look at the job.
+	crashMidRewrite(t, s, false)
+	s = NewStoreAt(s.dir)
+	if _, err := s.ApplyRetention(config.History{MaxRecords: 3}, nil, epoch); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.GetJobRecords("web", 0)); n != 3 {
+		t.Errorf("after restart: got %d records, want 3", n)
+	}
+	if report, _ := s.Fsck(false); len(report.Issues) != 0 {
+		t.Errorf("after restart: %+v", report.Issues)
+	}
+}
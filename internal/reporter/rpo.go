@@ -0,0 +1,56 @@
+package reporter
+
+import (
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// RPO is a job's recovery-point compliance: how old its newest successful
+// backup is compared with the job's max_age.
+type RPO struct {
+	MaxAge      time.Duration
+	LastSuccess *config.RunRecord
+	Age         time.Duration
+}
+
+// Stale reports whether the job has no successful backup within MaxAge.
+func (r RPO) Stale() bool {
+	return r.LastSuccess == nil || r.Age > r.MaxAge
+}
+
+// CheckRPO evaluates a job against its max_age using its run records. The
+// second result is false when the job has no max_age.
+func CheckRPO(job *config.Job, records []config.RunRecord, now time.Time) (RPO, bool) {
+	return RPOFor(job, LastSuccess(records, job.Name), now)
+}
+
+// RPOFor evaluates a job against its max_age given its last successful run,
+// which may be nil. The second result is false when the job has no max_age.
+func RPOFor(job *config.Job, last *config.RunRecord, now time.Time) (RPO, bool) {
+	if job.MaxAge <= 0 {
+		return RPO{}, false
+	}
+
+	rpo := RPO{MaxAge: job.MaxAge}
+	if last != nil {
+		rpo.LastSuccess = last
+		rpo.Age = now.Sub(last.CompletedAt)
+	}
+	return rpo, true
+}
+
+// LastSuccess returns the job's most recently completed successful real run.
+func LastSuccess(records []config.RunRecord, jobName string) *config.RunRecord {
+	var last *config.RunRecord
+	for i := range records {
+		r := &records[i]
+		if r.JobName != jobName || !r.Success || r.DryRun {
+			continue
+		}
+		if last == nil || r.CompletedAt.After(last.CompletedAt) {
+			last = r
+		}
+	}
+	return last
+}
@@ -0,0 +1,43 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+func TestCheckRPO(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	job := config.Job{Name: "web", MaxAge: 24 * time.Hour}
+
+	records := []config.RunRecord{
+		{JobName: "web", CompletedAt: now.Add(-30 * time.Hour), Success: true},
+		{JobName: "web", CompletedAt: now.Add(-2 * time.Hour), Success: false},
+		{JobName: "web", CompletedAt: now.Add(-time.Hour), Success: true, DryRun: true},
+		{JobName: "db", CompletedAt: now.Add(-time.Hour), Success: true},
+	}
+
+	rpo, ok := CheckRPO(&job, records, now)
+	if !ok {
+		t.Fatal("expected RPO to be tracked")
+	}
+	if !rpo.Stale() || rpo.Age != 30*time.Hour {
+		t.Errorf("stale = %v, age = %v; want stale at 30h", rpo.Stale(), rpo.Age)
+	}
+
+	records = append(records, config.RunRecord{JobName: "web", CompletedAt: now.Add(-3 * time.Hour), Success: true})
+	rpo, _ = CheckRPO(&job, records, now)
+	if rpo.Stale() || rpo.Age != 3*time.Hour {
+		t.Errorf("stale = %v, age = %v; want fresh at 3h", rpo.Stale(), rpo.Age)
+	}
+
+	never, _ := CheckRPO(&config.Job{Name: "new", MaxAge: time.Hour}, records, now)
+	if !never.Stale() || never.LastSuccess != nil {
+		t.Errorf("job without a success should be stale: %+v", never)
+	}
+
+	if _, ok := CheckRPO(&config.Job{Name: "web"}, records, now); ok {
+		t.Error("job without max_age should not be tracked")
+	}
+}
@@ -1,19 +1,24 @@
 package reporter
 
 import (
+	"sort"
 	"time"
 
 	"github.com/klederson/keeper/internal/config"
 )
 
+// Stats summarises a set of runs. SuccessCount, WarningCount and FailCount
+// partition TotalRuns; SuccessRate counts warnings as successful, since
+// those runs completed.
 type Stats struct {
-	TotalRuns        int
-	SuccessCount     int
-	FailCount        int
-	SuccessRate      float64
-	TotalBytes       int64
-	AvgDuration      time.Duration
-	LastRun          *config.RunRecord
+	TotalRuns    int               `json:"total_runs"`
+	SuccessCount int               `json:"success_count"`
+	WarningCount int               `json:"warning_count"`
+	FailCount    int               `json:"fail_count"`
+	SuccessRate  float64           `json:"success_rate"`
+	TotalBytes   int64             `json:"total_bytes"`
+	AvgDuration  time.Duration     `json:"avg_duration"`
+	LastRun      *config.RunRecord `json:"last_run,omitempty"`
 }
 
 func CalculateStats(records []config.RunRecord, since time.Time) Stats {
@@ -30,9 +35,12 @@ func CalculateStats(records []config.RunRecord, since time.Time) Stats {
 		}
 
 		stats.TotalRuns++
-		if r.Success {
+		switch r.Status() {
+		case config.OutcomeSuccess:
 			stats.SuccessCount++
-		} else {
+		case config.OutcomeWarning:
+			stats.WarningCount++
+		default:
 			stats.FailCount++
 		}
 
@@ -45,14 +53,94 @@ func CalculateStats(records []config.RunRecord, since time.Time) Stats {
 	}
 
 	if stats.TotalRuns > 0 {
-		stats.SuccessRate = float64(stats.SuccessCount) / float64(stats.TotalRuns) * 100
+		stats.SuccessRate = float64(stats.SuccessCount+stats.WarningCount) / float64(stats.TotalRuns) * 100
 		stats.AvgDuration = totalDuration / time.Duration(stats.TotalRuns)
 	}
 
 	return stats
 }
 
-func CalculateJobStats(store *Store, jobName string) Stats {
-	records := store.GetJobRecords(jobName, 0)
-	return CalculateStats(records, time.Time{})
+// JobStats is one job's Stats plus the figures 'keeper stats' and the
+// dashboard show for it. Throughput is bytes transferred per second of run
+// time. ChangeRate is the average share of the source, in percent, that a
+// completed run had to transfer.
+type JobStats struct {
+	Name string `json:"name"`
+	Stats
+	DurationP50   time.Duration     `json:"duration_p50"`
+	DurationP95   time.Duration     `json:"duration_p95"`
+	DurationMax   time.Duration     `json:"duration_max"`
+	Throughput    float64           `json:"throughput"`
+	ChangeRate    float64           `json:"change_rate"`
+	LastSuccess   *config.RunRecord `json:"last_success,omitempty"`
+	CurrentStreak int               `json:"current_failure_streak"`
+	LongestStreak Streak            `json:"longest_failure_streak"`
+}
+
+// JobStatsFor summarises one job's runs that started at or after since.
+// LastSuccess and CurrentStreak look at every record, so a job failing for
+// longer than the period still shows when it last worked. Records may be in
+// any order.
+func JobStatsFor(name string, records []config.RunRecord, since time.Time) JobStats {
+	var all, runs []config.RunRecord
+	for _, r := range records {
+		if r.DryRun {
+			continue
+		}
+		all = append(all, r)
+		if !since.IsZero() && r.StartedAt.Before(since) {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	byStart := func(rs []config.RunRecord) {
+		sort.SliceStable(rs, func(i, j int) bool { return rs[i].StartedAt.Before(rs[j].StartedAt) })
+	}
+	byStart(all)
+	byStart(runs)
+
+	js := JobStats{Name: name, Stats: CalculateStats(runs, since)}
+
+	var (
+		durations   []time.Duration
+		elapsed     time.Duration
+		transferred int64
+		changeSum   float64
+		changeRuns  int
+	)
+	for i := range runs {
+		r := &runs[i]
+		d := r.CompletedAt.Sub(r.StartedAt)
+		durations = append(durations, d)
+		elapsed += d
+		transferred += r.BytesTransferred
+
+		if r.Status() == config.OutcomeFailure {
+			continue
+		}
+		if r.BytesTotal > 0 {
+			changeSum += float64(r.BytesTransferred) / float64(r.BytesTotal) * 100
+			changeRuns++
+		}
+	}
+
+	js.DurationP50 = percentile(durations, 50)
+	js.DurationP95 = percentile(durations, 95)
+	js.DurationMax = percentile(durations, 100)
+	if elapsed > 0 {
+		js.Throughput = float64(transferred) / elapsed.Seconds()
+	}
+	if changeRuns > 0 {
+		js.ChangeRate = changeSum / float64(changeRuns)
+	}
+	js.LongestStreak, _ = failureStreaks(runs)
+	_, js.CurrentStreak = failureStreaks(all)
+	js.LastSuccess = LastSuccess(all, name)
+	return js
+}
+
+// CalculateJobStats loads a job's history and summarises it since the given
+// time; a zero since covers all of it.
+func CalculateJobStats(store *Store, jobName string, since time.Time) JobStats {
+	return JobStatsFor(jobName, store.GetJobRecords(jobName, 0), since)
 }
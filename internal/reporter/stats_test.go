@@ -124,3 +124,90 @@ func TestCalculateStatsDryRunExcluded(t *testing.T) {
 		t.Errorf("TotalRuns = %d, want 1 (dry runs excluded)", stats.TotalRuns)
 	}
 }
+
+func TestCalculateStatsWarnings(t *testing.T) {
+	now := time.Now()
+	records := []config.RunRecord{
+		{StartedAt: now, CompletedAt: now, Success: true, Outcome: config.OutcomeSuccess},
+		{StartedAt: now, CompletedAt: now, Success: true, Outcome: config.OutcomeWarning, Warnings: []string{"file has vanished"}},
+		{StartedAt: now, CompletedAt: now, Success: true}, // written before outcomes
+		{StartedAt: now, CompletedAt: now, Success: false},
+	}
+
+	stats := CalculateStats(records, time.Time{})
+	if stats.SuccessCount != 2 || stats.WarningCount != 1 || stats.FailCount != 1 {
+		t.Errorf("counts = %d/%d/%d, want 2/1/1", stats.SuccessCount, stats.WarningCount, stats.FailCount)
+	}
+	if stats.SuccessRate != 75 {
+		t.Errorf("SuccessRate = %v, want 75", stats.SuccessRate)
+	}
+}
+
+func TestJobStatsFor(t *testing.T) {
+	base := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	run := func(day int, minutes int, success bool, transferred, total int64) config.RunRecord {
+		start := base.AddDate(0, 0, day)
+		return config.RunRecord{
+			JobName:          "home",
+			StartedAt:        start,
+			CompletedAt:      start.Add(time.Duration(minutes) * time.Minute),
+			Success:          success,
+			BytesTransferred: transferred,
+			BytesTotal:       total,
+		}
+	}
+	// Newest first, as the store returns them.
+	records := []config.RunRecord{
+		run(5, 1, false, 0, 0),
+		run(4, 1, false, 0, 0),
+		run(3, 10, true, 600, 1000),
+		run(2, 1, false, 0, 0),
+		run(1, 1, false, 0, 0),
+		run(0, 4, true, 0, 0),
+		run(-1, 1, false, 0, 0),
+	}
+	records[0].Outcome = config.OutcomeFailure
+
+	js := JobStatsFor("home", records, base.AddDate(0, 0, 1))
+
+	if js.TotalRuns != 5 {
+		t.Fatalf("TotalRuns = %d, want 5", js.TotalRuns)
+	}
+	if js.DurationP50 != time.Minute || js.DurationMax != 10*time.Minute {
+		t.Errorf("durations p50=%v max=%v, want 1m/10m", js.DurationP50, js.DurationMax)
+	}
+	if want := 600.0 / (14 * 60); math.Abs(js.Throughput-want) > 1e-9 {
+		t.Errorf("Throughput = %v, want %v", js.Throughput, want)
+	}
+	if js.ChangeRate != 60 {
+		t.Errorf("ChangeRate = %v, want 60", js.ChangeRate)
+	}
+	if js.LastSuccess == nil || !js.LastSuccess.StartedAt.Equal(base.AddDate(0, 0, 3)) {
+		t.Errorf("LastSuccess = %+v", js.LastSuccess)
+	}
+	if js.CurrentStreak != 2 || js.LongestStreak.Runs != 2 || !js.LongestStreak.Start.Equal(base.AddDate(0, 0, 1)) {
+		t.Errorf("streaks current=%d longest=%+v", js.CurrentStreak, js.LongestStreak)
+	}
+}
+
+func TestJobStatsForLongFailure(t *testing.T) {
+	base := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	var records []config.RunRecord
+	for day := 0; day < 10; day++ {
+		start := base.AddDate(0, 0, day)
+		records = append(records, config.RunRecord{JobName: "home", StartedAt: start, CompletedAt: start.Add(time.Minute), Success: day == 0})
+	}
+
+	// The job has been failing since before the period began.
+	js := JobStatsFor("home", records, base.AddDate(0, 0, 7))
+
+	if js.TotalRuns != 3 || js.LongestStreak.Runs != 3 {
+		t.Errorf("windowed runs = %d, longest streak = %d; want 3, 3", js.TotalRuns, js.LongestStreak.Runs)
+	}
+	if js.CurrentStreak != 9 {
+		t.Errorf("CurrentStreak = %d, want 9", js.CurrentStreak)
+	}
+	if js.LastSuccess == nil || !js.LastSuccess.StartedAt.Equal(base) {
+		t.Errorf("LastSuccess = %+v, want the run on day 0", js.LastSuccess)
+	}
+}
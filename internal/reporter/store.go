@@ -2,98 +2,449 @@ package reporter
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/klederson/keeper/internal/config"
 )
 
+const (
+	// legacyFile is the single JSONL history used before per-job segments.
+	legacyFile = "history.jsonl"
+	historyDir = "history"
+	lockName   = "history.lock"
+
+	// segmentBytes is the size at which a job's active segment is closed and
+	// a new one started. Queries read whole segments, so this bounds the
+	// work done beyond the records asked for.
+	segmentBytes = 256 << 10
+	segmentExt   = ".jsonl"
+)
+
+// Store keeps run history as per-job directories of append-only JSONL
+// segments, numbered in write order:
+//
+//	history/<job>/000001.jsonl
+//	history/<job>/000002.jsonl
+//
+// Reading a job's newest records only touches its newest segments, so
+// queries cost O(limit) rather than a scan of every run ever recorded.
 type Store struct {
-	path string
+	dir     string
+	migrate sync.Once
 }
 
 func NewStore() *Store {
-	return &Store{
-		path: filepath.Join(config.DataDir(), "history.jsonl"),
+	return NewStoreAt(config.DataDir())
+}
+
+// NewStoreAt returns a store rooted at dataDir instead of the default.
+func NewStoreAt(dataDir string) *Store {
+	return &Store{dir: dataDir}
+}
+
+func (s *Store) root() string {
+	return filepath.Join(s.dir, historyDir)
+}
+
+func (s *Store) jobDir(jobName string) string {
+	return filepath.Join(s.root(), escapeJob(jobName))
+}
+
+// escapeJob turns a job name into a safe directory name.
+func escapeJob(name string) string {
+	escaped := url.QueryEscape(name)
+	if strings.HasPrefix(escaped, ".") {
+		escaped = "%2E" + escaped[1:]
+	}
+	return escaped
+}
+
+// Append adds a record to its job's history. The write happens under an
+// exclusive lock shared by every keeper process, as a single write of one
+// complete line, and is synced before Append returns.
+func (s *Store) Append(record config.RunRecord) error {
+	s.ready()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling record: %w", err)
 	}
+	data = append(data, '\n')
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := appendToJob(s.jobDir(record.JobName), data); err != nil {
+		return fmt.Errorf("writing history for %q: %w", record.JobName, err)
+	}
+	return nil
 }
 
-func (s *Store) Append(record config.RunRecord) {
-	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
-		slog.Error("creating history dir", "error", err)
-		return
+// lock serialises writers across processes. It must be held while
+// appending, migrating or repairing history.
+func (s *Store) lock() (func(), error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating data dir: %w", err)
 	}
+	unlock, err := lockFile(filepath.Join(s.dir, lockName))
+	if err != nil {
+		return nil, fmt.Errorf("locking history: %w", err)
+	}
+	return unlock, nil
+}
 
-	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// appendToJob appends data to the job's newest segment, starting a new
+// segment once the current one reaches segmentBytes. If an earlier write
+// was torn and left the segment without a final newline, the partial line
+// is terminated first so the new record stays readable.
+func appendToJob(dir string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		return err
+	}
+	seq := 1
+	if n := len(segments); n > 0 {
+		seq = segments[n-1]
+		if info, err := os.Stat(segmentPath(dir, seq)); err == nil && info.Size() >= segmentBytes {
+			seq++
+		}
+	}
+
+	f, err := os.OpenFile(segmentPath(dir, seq), os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
 	if err != nil {
-		slog.Error("opening history file", "error", err)
-		return
+		return err
 	}
 	defer f.Close()
 
-	data, err := json.Marshal(record)
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func segmentPath(dir string, seq int) string {
+	return filepath.Join(dir, fmt.Sprintf("%06d%s", seq, segmentExt))
+}
+
+// listSegments returns the job's segment numbers in ascending order.
+func listSegments(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		slog.Error("marshaling record", "error", err)
-		return
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	f.Write(data)
-	f.Write([]byte("\n"))
+	var seqs []int
+	for _, e := range entries {
+		base, ok := strings.CutSuffix(e.Name(), segmentExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if seq, err := strconv.Atoi(base); err == nil {
+			seqs = append(seqs, seq)
+		}
+	}
+	sort.Ints(seqs)
+	return seqs, nil
 }
 
+// Jobs lists the jobs that have recorded history.
+func (s *Store) Jobs() []string {
+	s.ready()
+
+	entries, err := os.ReadDir(s.root())
+	if err != nil {
+		return nil
+	}
+	var jobs []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if name, err := url.QueryUnescape(e.Name()); err == nil {
+			jobs = append(jobs, name)
+		}
+	}
+	return jobs
+}
+
+// LoadAll returns every record, oldest first.
 func (s *Store) LoadAll() []config.RunRecord {
-	f, err := os.Open(s.path)
+	var all []config.RunRecord
+	for _, job := range s.Jobs() {
+		all = append(all, s.GetJobRecords(job, 0)...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].CompletedAt.Before(all[j].CompletedAt)
+	})
+	return all
+}
+
+// GetJobRecords returns up to limit of the job's records, newest first. A
+// limit of 0 returns all of them.
+func (s *Store) GetJobRecords(jobName string, limit int) []config.RunRecord {
+	s.ready()
+
+	dir := s.jobDir(jobName)
+	segments, err := listSegments(dir)
 	if err != nil {
+		slog.Error("listing history", "job", jobName, "error", err)
 		return nil
 	}
-	defer f.Close()
 
 	var records []config.RunRecord
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment, err := readSegment(segmentPath(dir, segments[i]))
+		if err != nil {
+			slog.Error("reading history", "job", jobName, "error", err)
+			continue
+		}
+		for j := len(segment) - 1; j >= 0; j-- {
+			records = append(records, segment[j])
+			if limit > 0 && len(records) >= limit {
+				return records
+			}
+		}
+	}
+	return records
+}
 
-	for scanner.Scan() {
-		var r config.RunRecord
-		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+// LastSuccess returns the job's newest successful real run, reading only as
+// many segments back as it takes to find one.
+func (s *Store) LastSuccess(jobName string) *config.RunRecord {
+	s.ready()
+
+	dir := s.jobDir(jobName)
+	segments, err := listSegments(dir)
+	if err != nil {
+		slog.Error("listing history", "job", jobName, "error", err)
+		return nil
+	}
+
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment, err := readSegment(segmentPath(dir, segments[i]))
+		if err != nil {
+			slog.Error("reading history", "job", jobName, "error", err)
 			continue
 		}
-		records = append(records, r)
+		if last := LastSuccess(segment, jobName); last != nil {
+			return last
+		}
+	}
+	return nil
+}
+
+// GetRecentRecords returns the newest records across all jobs, newest first.
+func (s *Store) GetRecentRecords(limit int) []config.RunRecord {
+	var recent []config.RunRecord
+	for _, job := range s.Jobs() {
+		recent = append(recent, s.GetJobRecords(job, limit)...)
+	}
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].CompletedAt.After(recent[j].CompletedAt)
+	})
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent
+}
+
+func readSegment(path string) ([]config.RunRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer f.Close()
 
+	records, _, err := scanRecords(f)
+	return records, err
+}
+
+// decodeRecords reads JSONL records, skipping lines that don't parse.
+func decodeRecords(r io.Reader) []config.RunRecord {
+	records, _, _ := scanRecords(r)
 	return records
 }
 
-func (s *Store) GetJobRecords(jobName string, limit int) []config.RunRecord {
-	all := s.LoadAll()
-
-	var filtered []config.RunRecord
-	for i := len(all) - 1; i >= 0; i-- {
-		if all[i].JobName == jobName {
-			filtered = append(filtered, all[i])
-			if limit > 0 && len(filtered) >= limit {
-				break
+// badLine is a line of a history file that isn't a valid record.
+type badLine struct {
+	Number int
+	Data   []byte
+}
+
+// scanRecords decodes JSONL records of any length. Lines that don't parse,
+// including a partial final line left by a write still in progress or a
+// crash, are returned separately instead of failing the read.
+func scanRecords(r io.Reader) ([]config.RunRecord, []badLine, error) {
+	var records []config.RunRecord
+	var bad []badLine
+	br := bufio.NewReader(r)
+
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var rec config.RunRecord
+			if jsonErr := json.Unmarshal(line, &rec); jsonErr != nil {
+				bad = append(bad, badLine{Number: n, Data: line})
+			} else {
+				records = append(records, rec)
 			}
 		}
+		if err == io.EOF {
+			return records, bad, nil
+		}
+		if err != nil {
+			return records, bad, err
+		}
 	}
+}
 
-	return filtered
+// ready migrates the legacy history file on first use, and puts back
+// history a crash left aside mid-rewrite.
+func (s *Store) ready() {
+	s.migrate.Do(func() {
+		if err := s.migrateLegacy(); err != nil {
+			slog.Error("migrating history", "error", err)
+		}
+		if err := s.recoverRewrites(); err != nil {
+			slog.Error("recovering history", "error", err)
+		}
+	})
 }
 
-func (s *Store) GetRecentRecords(limit int) []config.RunRecord {
-	all := s.LoadAll()
+// recoverRewrites runs recoverRewrite for every job a crash interrupted,
+// taking the history lock only when there is something to do.
+func (s *Store) recoverRewrites() error {
+	if len(s.interruptedRewrites()) == 0 {
+		return nil
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	start := len(all) - limit
-	if start < 0 {
-		start = 0
+	for _, escaped := range s.interruptedRewrites() {
+		if err := s.recoverRewrite(escaped); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// Return most recent first
-	result := make([]config.RunRecord, 0, limit)
-	for i := len(all) - 1; i >= start; i-- {
-		result = append(result, all[i])
+// migrateLegacy splits history.jsonl into per-job segments. It builds the
+// new tree in a temporary directory and renames it into place under the
+// history lock, so readers see either no history directory or a complete
+// one. The old file is kept as history.jsonl.migrated.
+func (s *Store) migrateLegacy() error {
+	legacy := filepath.Join(s.dir, legacyFile)
+	if _, err := os.Stat(legacy); os.IsNotExist(err) {
+		return nil
 	}
 
-	return result
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := os.Stat(s.root()); err == nil {
+		// Already migrated by another process; only the rename is left.
+		return renameMigrated(legacy)
+	}
+
+	f, err := os.Open(legacy)
+	if err != nil {
+		return err
+	}
+	records := decodeRecords(f)
+	f.Close()
+
+	tmp, err := os.MkdirTemp(s.dir, historyDir+".migrating-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	byJob := make(map[string][]byte)
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		byJob[r.JobName] = append(append(byJob[r.JobName], data...), '\n')
+	}
+	for job, data := range byJob {
+		if err := writeSegments(filepath.Join(tmp, escapeJob(job)), data); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(tmp, s.root()); err != nil {
+		if _, statErr := os.Stat(s.root()); statErr == nil {
+			return renameMigrated(legacy)
+		}
+		return err
+	}
+	slog.Info("migrated run history to per-job segments", "records", len(records), "jobs", len(byJob))
+	return renameMigrated(legacy)
+}
+
+// writeSegments writes JSONL data into numbered segments of about
+// segmentBytes each, splitting only at line boundaries.
+func writeSegments(dir string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for seq := 1; len(data) > 0; seq++ {
+		n := len(data)
+		if n > segmentBytes {
+			n = segmentBytes + bytes.IndexByte(data[segmentBytes:], '\n') + 1
+			if n <= segmentBytes {
+				n = len(data)
+			}
+		}
+		if err := os.WriteFile(segmentPath(dir, seq), data[:n], 0644); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+func renameMigrated(legacy string) error {
+	if err := os.Rename(legacy, legacy+".migrated"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
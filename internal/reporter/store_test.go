@@ -0,0 +1,289 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func record(job string, i int) config.RunRecord {
+	start := epoch.Add(time.Duration(i) * time.Hour)
+	return config.RunRecord{
+		JobName:          job,
+		StartedAt:        start,
+		CompletedAt:      start.Add(time.Minute),
+		Success:          i%7 != 0,
+		FilesTotal:       1000 + i,
+		BytesTotal:       int64(i) << 20,
+		BytesTransferred: int64(i) << 10,
+	}
+}
+
+func TestStoreQueries(t *testing.T) {
+	s := NewStoreAt(t.TempDir())
+	for i := 0; i < 30; i++ {
+		job := []string{"web", "db", "../odd name"}[i%3]
+		s.Append(record(job, i))
+	}
+
+	web := s.GetJobRecords("web", 3)
+	if len(web) != 3 || web[0].CompletedAt != record("web", 27).CompletedAt || web[2].CompletedAt != record("web", 21).CompletedAt {
+		t.Errorf("GetJobRecords(web, 3) = %v", web)
+	}
+	if n := len(s.GetJobRecords("../odd name", 0)); n != 10 {
+		t.Errorf("odd job has %d records, want 10", n)
+	}
+	if len(s.GetJobRecords("missing", 5)) != 0 {
+		t.Error("unknown job should have no records")
+	}
+
+	recent := s.GetRecentRecords(4)
+	if len(recent) != 4 || recent[0].JobName != "../odd name" || recent[1].JobName != "db" || recent[2].JobName != "web" {
+		t.Errorf("GetRecentRecords(4) = %v", recent)
+	}
+
+	all := s.LoadAll()
+	if len(all) != 30 {
+		t.Fatalf("LoadAll() returned %d records, want 30", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].CompletedAt.Before(all[i-1].CompletedAt) {
+			t.Fatal("LoadAll() is not oldest first")
+		}
+	}
+
+	failed := record("web", 30)
+	failed.Success = false
+	s.Append(failed)
+	if last := s.LastSuccess("web"); last == nil || last.CompletedAt != record("web", 27).CompletedAt {
+		t.Errorf("LastSuccess(web) = %v, want record 27", last)
+	}
+	if s.LastSuccess("missing") != nil {
+		t.Error("unknown job should have no last success")
+	}
+}
+
+func TestStoreRollsSegments(t *testing.T) {
+	s := NewStoreAt(t.TempDir())
+	n := 0
+	for ; n < 10000; n++ {
+		s.Append(record("web", n))
+		if segs, _ := listSegments(s.jobDir("web")); len(segs) == 3 {
+			break
+		}
+	}
+
+	records := s.GetJobRecords("web", 0)
+	if len(records) != n+1 {
+		t.Fatalf("got %d records across segments, want %d", len(records), n+1)
+	}
+	if records[0].FilesTotal != record("web", n).FilesTotal || records[n].FilesTotal != record("web", 0).FilesTotal {
+		t.Error("records across segments are not newest first")
+	}
+}
+
+func TestStoreMigratesLegacyHistory(t *testing.T) {
+	dir := t.TempDir()
+	writeLegacy(t, dir, 3, 2000)
+
+	s := NewStoreAt(dir)
+	if n := len(s.GetJobRecords("job-1", 0)); n != 2000 {
+		t.Errorf("job-1 has %d records after migration, want 2000", n)
+	}
+	if segs, _ := listSegments(s.jobDir("job-1")); len(segs) < 2 {
+		t.Errorf("expected large history to be split into segments, got %v", segs)
+	}
+	if _, err := os.Stat(filepath.Join(dir, legacyFile)); !os.IsNotExist(err) {
+		t.Error("legacy history file was not moved aside")
+	}
+	if _, err := os.Stat(filepath.Join(dir, legacyFile+".migrated")); err != nil {
+		t.Errorf("legacy history backup missing: %v", err)
+	}
+
+	// A second store must not migrate again or duplicate records.
+	s2 := NewStoreAt(dir)
+	s2.Append(record("job-1", 5000))
+	if n := len(s2.LoadAll()); n != 6001 {
+		t.Errorf("LoadAll() = %d records, want 6001", n)
+	}
+}
+
+// writeLegacy writes a history.jsonl with jobs*perJob records interleaved
+// by job, as the old store appended them.
+func writeLegacy(tb testing.TB, dir string, jobs, perJob int) string {
+	tb.Helper()
+	path := filepath.Join(dir, legacyFile)
+	f, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for i := 0; i < perJob; i++ {
+		for j := 0; j < jobs; j++ {
+			enc.Encode(record(fmt.Sprintf("job-%d", j), i))
+		}
+	}
+	return path
+}
+
+// legacyJobRecords is how the single-file store answered GetJobRecords:
+// decode the whole file, then filter.
+func legacyJobRecords(path, job string, limit int) []config.RunRecord {
+	f, _ := os.Open(path)
+	defer f.Close()
+	all := decodeRecords(f)
+
+	var filtered []config.RunRecord
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].JobName == job {
+			filtered = append(filtered, all[i])
+			if limit > 0 && len(filtered) >= limit {
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+const benchJobs, benchPerJob = 20, 1000
+
+// BenchmarkGetJobRecords compares fetching a job's last run, as `keeper
+// status` does for every job, from the legacy file and from segments.
+func BenchmarkGetJobRecords(b *testing.B) {
+	dir := b.TempDir()
+	path := writeLegacy(b, dir, benchJobs, benchPerJob)
+	legacyCopy := filepath.Join(b.TempDir(), legacyFile)
+	data, _ := os.ReadFile(path)
+	os.WriteFile(legacyCopy, data, 0644)
+
+	s := NewStoreAt(dir)
+	s.GetJobRecords("job-0", 1) // migrate outside the timer
+
+	b.Run("legacy", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			legacyJobRecords(legacyCopy, "job-7", 1)
+		}
+	})
+	b.Run("segments", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.GetJobRecords("job-7", 1)
+		}
+	})
+}
+
+// BenchmarkStatusScan fetches the last run of every job, the pattern used by
+// status, list and each dashboard tick.
+func BenchmarkStatusScan(b *testing.B) {
+	dir := b.TempDir()
+	path := writeLegacy(b, dir, benchJobs, benchPerJob)
+	legacyCopy := filepath.Join(b.TempDir(), legacyFile)
+	data, _ := os.ReadFile(path)
+	os.WriteFile(legacyCopy, data, 0644)
+
+	s := NewStoreAt(dir)
+	s.GetJobRecords("job-0", 1)
+
+	b.Run("legacy", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < benchJobs; j++ {
+				legacyJobRecords(legacyCopy, fmt.Sprintf("job-%d", j), 1)
+			}
+		}
+	})
+	b.Run("segments", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < benchJobs; j++ {
+				s.GetJobRecords(fmt.Sprintf("job-%d", j), 1)
+			}
+		}
+	})
+}
+
+func TestConcurrentAppends(t *testing.T) {
+	dir := t.TempDir()
+	const writers, each = 8, 50
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// A store per writer stands in for separate keeper processes.
+			s := NewStoreAt(dir)
+			for i := 0; i < each; i++ {
+				r := record("web", w*each+i)
+				r.Errors = []string{strings.Repeat("x", 4096)} // lines larger than a pipe buffer
+				if err := s.Append(r); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	s := NewStoreAt(dir)
+	if n := len(s.GetJobRecords("web", 0)); n != writers*each {
+		t.Errorf("read back %d records, want %d", n, writers*each)
+	}
+	report, err := s.Fsck(false)
+	if err != nil || len(report.Issues) != 0 {
+		t.Errorf("Fsck after concurrent appends: %+v, %v", report.Issues, err)
+	}
+}
+
+func TestTornWriteAndFsck(t *testing.T) {
+	s := NewStoreAt(t.TempDir())
+	if err := s.Append(record("web", 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash halfway through writing a record.
+	seg := segmentPath(s.jobDir("web"), 1)
+	f, _ := os.OpenFile(seg, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"job_name":"web","started_at":"2026-`)
+	f.Close()
+
+	if n := len(s.GetJobRecords("web", 0)); n != 1 {
+		t.Fatalf("partial trailing line: got %d records, want 1", n)
+	}
+
+	// The next append must not be glued onto the torn line.
+	if err := s.Append(record("web", 2)); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.GetJobRecords("web", 0)); n != 2 {
+		t.Fatalf("after append: got %d records, want 2", n)
+	}
+
+	report, err := s.Fsck(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Line != 2 || report.Repaired {
+		t.Fatalf("Fsck() issues = %+v", report.Issues)
+	}
+
+	report, err = s.Fsck(true)
+	if err != nil || !report.Repaired {
+		t.Fatalf("Fsck(repair) = %+v, %v", report, err)
+	}
+	if report, _ := s.Fsck(false); len(report.Issues) != 0 || report.Records != 2 {
+		t.Errorf("after repair: %+v", report)
+	}
+	saved, err := os.ReadFile(seg + corruptExt)
+	if err != nil || !strings.Contains(string(saved), `"started_at":"2026-`) {
+		t.Errorf("removed line not kept: %q, %v", saved, err)
+	}
+}
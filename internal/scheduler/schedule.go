@@ -0,0 +1,283 @@
+package scheduler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+var cronParser = cron.NewParser(
+	cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// maxWindowSearch bounds how many schedule ticks are inspected when looking
+// for one that falls inside a run window.
+const maxWindowSearch = 10000
+
+// ParseSchedule builds the timer for a job: its cron expression or interval,
+// evaluated in the job's time zone, shifted by its stagger offset and clipped
+// to its run window. Random jitter is applied separately at run time.
+func ParseSchedule(job *config.Job) (cron.Schedule, error) {
+	loc := job.Location()
+
+	var sched cron.Schedule
+	switch {
+	case job.Interval > 0:
+		sched = intervalSchedule{every: job.Interval, loc: loc}
+	case job.Schedule != "":
+		spec := job.Schedule
+		if job.Timezone != "" && !strings.HasPrefix(spec, "TZ=") && !strings.HasPrefix(spec, "CRON_TZ=") {
+			spec = "CRON_TZ=" + job.Timezone + " " + spec
+		}
+		parsed, err := cronParser.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		sched = parsed
+	default:
+		return nil, fmt.Errorf("job %q has no schedule or interval", job.Name)
+	}
+
+	if offset := StaggerOffset(job); offset > 0 {
+		sched = offsetSchedule{inner: sched, offset: offset}
+	}
+
+	if job.Window != nil {
+		w, err := parseWindow(job.Window, loc)
+		if err != nil {
+			return nil, err
+		}
+		sched = windowSchedule{inner: sched, window: w}
+	}
+
+	return sched, nil
+}
+
+// NextRun returns when the job will next be started by the scheduler, or
+// the zero time if it has no timer.
+func NextRun(job *config.Job, now time.Time) time.Time {
+	if !job.HasSchedule() {
+		return time.Time{}
+	}
+	sched, err := ParseSchedule(job)
+	if err != nil {
+		return time.Time{}
+	}
+	return sched.Next(now)
+}
+
+// StaggerOffset returns a stable delay in [0, job.Stagger) derived from the
+// hostname and job name, so machines sharing a config spread their runs out
+// instead of all hitting the backup server at the same minute.
+func StaggerOffset(job *config.Job) time.Duration {
+	if job.Stagger <= 0 {
+		return 0
+	}
+	host, _ := os.Hostname()
+
+	h := fnv.New64a()
+	h.Write([]byte(host + "/" + job.Name))
+	offset := time.Duration(h.Sum64() % uint64(job.Stagger))
+	return offset.Truncate(time.Second)
+}
+
+// offsetSchedule shifts every tick of the inner schedule by a fixed offset.
+type offsetSchedule struct {
+	inner  cron.Schedule
+	offset time.Duration
+}
+
+func (s offsetSchedule) Next(t time.Time) time.Time {
+	next := s.inner.Next(t.Add(-s.offset))
+	if next.IsZero() {
+		return next
+	}
+	return next.Add(s.offset)
+}
+
+// intervalSchedule fires every `every`, aligned to midnight in loc so that
+// "every 90m" lands on the same wall-clock times each day.
+type intervalSchedule struct {
+	every time.Duration
+	loc   *time.Location
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc)
+	if s.every >= 24*time.Hour {
+		return t.Add(s.every).Truncate(time.Minute)
+	}
+
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.loc)
+	n := t.Sub(midnight)/s.every + 1
+	next := midnight.Add(n * s.every)
+
+	// Don't carry a partial slot over midnight; restart the grid each day.
+	if tomorrow := midnight.AddDate(0, 0, 1); !next.Before(tomorrow) {
+		return tomorrow
+	}
+	return next
+}
+
+// window is a daily [start, end) range measured in minutes after midnight.
+type window struct {
+	start, end int
+	loc        *time.Location
+}
+
+func parseWindow(w *config.Window, loc *time.Location) (window, error) {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return window{}, fmt.Errorf("window start: %w", err)
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return window{}, fmt.Errorf("window end: %w", err)
+	}
+	return window{
+		start: start.Hour()*60 + start.Minute(),
+		end:   end.Hour()*60 + end.Minute(),
+		loc:   loc,
+	}, nil
+}
+
+func (w window) minuteOfDay(t time.Time) int {
+	t = t.In(w.loc)
+	return t.Hour()*60 + t.Minute()
+}
+
+func (w window) contains(t time.Time) bool {
+	m := w.minuteOfDay(t)
+	if w.start < w.end {
+		return m >= w.start && m < w.end
+	}
+	return m >= w.start || m < w.end
+}
+
+func (w window) at(day time.Time, minute int) time.Time {
+	day = day.In(w.loc)
+	return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, w.loc)
+}
+
+// nextOpen returns the next time the window opens strictly after t.
+func (w window) nextOpen(t time.Time) time.Time {
+	open := w.at(t, w.start)
+	if !open.After(t) {
+		open = w.at(t.In(w.loc).AddDate(0, 0, 1), w.start)
+	}
+	return open
+}
+
+// closesAt returns when the window containing t ends.
+func (w window) closesAt(t time.Time) time.Time {
+	end := w.at(t, w.end)
+	if !end.After(t) {
+		end = w.at(t.In(w.loc).AddDate(0, 0, 1), w.end)
+	}
+	return end
+}
+
+// windowSchedule skips ticks of the inner schedule that fall outside the window.
+type windowSchedule struct {
+	inner  cron.Schedule
+	window window
+}
+
+func (s windowSchedule) Next(t time.Time) time.Time {
+	for i := 0; i < maxWindowSearch; i++ {
+		next := s.inner.Next(t)
+		if next.IsZero() || s.window.contains(next) {
+			return next
+		}
+		// Jump to just before the next opening instead of stepping tick by tick.
+		t = s.window.nextOpen(next).Add(-time.Second)
+	}
+	return time.Time{}
+}
+
+// PlannedRun is one upcoming scheduled start of a job.
+type PlannedRun struct {
+	Job    string
+	Host   string
+	At     time.Time
+	Jitter time.Duration
+}
+
+// Plan lists the next count scheduled runs across all jobs, in start order.
+func Plan(jobs []config.Job, from time.Time, count int) []PlannedRun {
+	var runs []PlannedRun
+
+	for i := range jobs {
+		job := &jobs[i]
+		if !job.HasSchedule() {
+			continue
+		}
+		sched, err := ParseSchedule(job)
+		if err != nil {
+			continue
+		}
+
+		t := from
+		for n := 0; n < count; n++ {
+			t = sched.Next(t)
+			if t.IsZero() {
+				break
+			}
+			runs = append(runs, PlannedRun{
+				Job:    job.Name,
+				Host:   job.Destination.Host,
+				At:     t,
+				Jitter: job.Jitter,
+			})
+		}
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].At.Before(runs[j].At)
+	})
+	if len(runs) > count {
+		runs = runs[:count]
+	}
+	return runs
+}
+
+// Overlap is a pair of planned runs from different jobs whose expected
+// execution times intersect on the same destination host.
+type Overlap struct {
+	Host string
+	A, B PlannedRun
+}
+
+// FindOverlaps reports planned runs to the same host that are expected to
+// run at the same time. Each run is assumed to occupy its start time plus
+// jitter plus the job's expected duration (at least a minute).
+func FindOverlaps(runs []PlannedRun, durations map[string]time.Duration) []Overlap {
+	end := func(r PlannedRun) time.Time {
+		d := durations[r.Job]
+		if d < time.Minute {
+			d = time.Minute
+		}
+		return r.At.Add(r.Jitter + d)
+	}
+
+	var overlaps []Overlap
+	for i := range runs {
+		for j := i + 1; j < len(runs); j++ {
+			a, b := runs[i], runs[j]
+			if a.Job == b.Job || a.Host != b.Host {
+				continue
+			}
+			if b.At.Before(end(a)) && a.At.Before(end(b)) {
+				overlaps = append(overlaps, Overlap{Host: a.Host, A: a, B: b})
+			}
+		}
+	}
+	return overlaps
+}
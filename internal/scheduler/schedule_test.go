@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+func TestIntervalSchedule(t *testing.T) {
+	loc := time.UTC
+	sched, err := ParseSchedule(&config.Job{Name: "i", Interval: 90 * time.Minute, Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		from time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 3, 1, 0, 0, 0, 0, loc), time.Date(2026, 3, 1, 1, 30, 0, 0, loc)},
+		{time.Date(2026, 3, 1, 1, 30, 0, 0, loc), time.Date(2026, 3, 1, 3, 0, 0, 0, loc)},
+		{time.Date(2026, 3, 1, 23, 45, 0, 0, loc), time.Date(2026, 3, 2, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		got := sched.Next(tt.from)
+		if !got.Equal(tt.want) {
+			t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleTimezone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+
+	sched, err := ParseSchedule(&config.Job{Name: "tz", Schedule: "0 2 * * *", Timezone: "Europe/Berlin"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	from := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	got := sched.Next(from)
+	want := time.Date(2026, 1, 11, 2, 0, 0, 0, berlin)
+	if !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestWindowSchedule(t *testing.T) {
+	job := &config.Job{
+		Name:     "win",
+		Interval: 90 * time.Minute,
+		Timezone: "UTC",
+		Window:   &config.Window{Start: "22:00", End: "06:00"},
+	}
+	sched, err := ParseSchedule(job)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		from time.Time
+		want time.Time
+	}{
+		// Outside the window: jump to the first tick at or after 22:00.
+		{time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 22, 30, 0, 0, time.UTC)},
+		// Inside the window across midnight.
+		{time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		// Last tick before the window closes.
+		{time.Date(2026, 3, 2, 4, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 4, 30, 0, 0, time.UTC)},
+		// 06:00 is the closing edge and not inside the window.
+		{time.Date(2026, 3, 2, 4, 30, 0, 0, time.UTC), time.Date(2026, 3, 2, 22, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got := sched.Next(tt.from)
+		if !got.Equal(tt.want) {
+			t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestWindowEdges(t *testing.T) {
+	w, err := parseWindow(&config.Window{Start: "22:00", End: "06:00"}, time.UTC)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 1, h, m, 0, 0, time.UTC) }
+
+	if !w.contains(at(23, 0)) || !w.contains(at(5, 59)) || w.contains(at(6, 0)) || w.contains(at(12, 0)) {
+		t.Error("contains() wrong for overnight window")
+	}
+	if got, want := w.closesAt(at(23, 0)), time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("closesAt = %v, want %v", got, want)
+	}
+	if got, want := w.nextOpen(at(7, 0)), at(22, 0); !got.Equal(want) {
+		t.Errorf("nextOpen = %v, want %v", got, want)
+	}
+}
+
+func TestStaggerOffset(t *testing.T) {
+	job := &config.Job{Name: "stagger", Stagger: 30 * time.Minute}
+
+	first := StaggerOffset(job)
+	if first < 0 || first >= job.Stagger {
+		t.Fatalf("offset %v outside [0, %v)", first, job.Stagger)
+	}
+	if again := StaggerOffset(job); again != first {
+		t.Errorf("offset not stable: %v then %v", first, again)
+	}
+	if StaggerOffset(&config.Job{Name: "stagger"}) != 0 {
+		t.Error("expected no offset without stagger")
+	}
+
+	sched, err := ParseSchedule(&config.Job{Name: "stagger", Schedule: "0 2 * * *", Timezone: "UTC", Stagger: job.Stagger})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	from := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC).Add(first)
+	if got := sched.Next(from); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestPlanAndOverlaps(t *testing.T) {
+	jobs := []config.Job{
+		{Name: "a", Schedule: "0 2 * * *", Timezone: "UTC", Destination: config.Destination{Host: "nas"}},
+		{Name: "b", Schedule: "0 2 * * *", Timezone: "UTC", Destination: config.Destination{Host: "nas"}},
+		{Name: "c", Schedule: "0 2 * * *", Timezone: "UTC", Destination: config.Destination{Host: "other"}},
+		{Name: "d", Schedule: "30 3 * * *", Timezone: "UTC", Destination: config.Destination{Host: "nas"}},
+		{Name: "manual", Destination: config.Destination{Host: "nas"}},
+	}
+
+	from := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	runs := Plan(jobs, from, 4)
+	if len(runs) != 4 {
+		t.Fatalf("expected 4 planned runs, got %d", len(runs))
+	}
+	for i := 1; i < len(runs); i++ {
+		if runs[i].At.Before(runs[i-1].At) {
+			t.Fatalf("runs not sorted: %v", runs)
+		}
+	}
+
+	overlaps := FindOverlaps(runs, map[string]time.Duration{"a": 10 * time.Minute})
+	if len(overlaps) != 1 || overlaps[0].Host != "nas" {
+		t.Fatalf("expected a/b overlap on nas, got %+v", overlaps)
+	}
+
+	// A long-running job reaches into the next one on the same host.
+	overlaps = FindOverlaps(runs, map[string]time.Duration{"a": 2 * time.Hour, "b": 2 * time.Hour})
+	if len(overlaps) != 3 {
+		t.Errorf("expected 3 overlaps with long durations, got %d", len(overlaps))
+	}
+}
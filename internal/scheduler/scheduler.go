@@ -2,66 +2,161 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/robfig/cron/v3"
 
+	"github.com/klederson/keeper/internal/backend"
 	"github.com/klederson/keeper/internal/backup"
 	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/notify"
+	"github.com/klederson/keeper/internal/pause"
 	"github.com/klederson/keeper/internal/reporter"
+	"github.com/klederson/keeper/internal/watcher"
 )
 
+// rpoCheckInterval is how often jobs are checked against their max_age.
+const rpoCheckInterval = time.Minute
+
 type Scheduler struct {
 	cron         *cron.Cron
 	orchestrator *backup.Orchestrator
 	store        *reporter.Store
+	pauses       *pause.Store
+	notifier     *notify.Dispatcher
 	mu           sync.Mutex
 	entries      map[string]cron.EntryID
+	digests      []cron.EntryID
+	watchers     map[string]*watcher.Watcher
+	resumes      map[string]*time.Timer
+	jobs         map[string]config.Job
+	rpoJobs      []config.Job
+	retention    config.History
+	jobNames     []string
+	stale        map[string]bool
+	started      time.Time
+	done         chan struct{}
+	stopping     bool
+	inflight     sync.WaitGroup
+	waiting      int
 }
 
 func New() *Scheduler {
 	return &Scheduler{
-		cron: cron.New(cron.WithParser(cron.NewParser(
-			cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
-		))),
+		cron:         cron.New(cron.WithParser(cronParser)),
 		orchestrator: backup.NewOrchestrator(),
 		store:        reporter.NewStore(),
+		pauses:       pause.NewStore(),
 		entries:      make(map[string]cron.EntryID),
+		watchers:     make(map[string]*watcher.Watcher),
+		resumes:      make(map[string]*time.Timer),
+		jobs:         make(map[string]config.Job),
+		stale:        make(map[string]bool),
+		done:         make(chan struct{}),
 	}
 }
 
+// AddJob schedules a job, replacing any job of the same name only once the
+// new one is set up, so a job that fails to schedule keeps its old entry.
 func (s *Scheduler) AddJob(job config.Job) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	jobCopy := job
-	entryID, err := s.cron.AddFunc(job.Schedule, func() {
-		s.runJob(&jobCopy)
-	})
-	if err != nil {
-		return err
+	var (
+		entryID   cron.EntryID
+		scheduled bool
+		next      time.Time
+	)
+	if job.HasSchedule() {
+		sched, err := ParseSchedule(&jobCopy)
+		if err != nil {
+			return err
+		}
+		entryID = s.cron.Schedule(sched, cron.FuncJob(func() {
+			s.setWaiting(1)
+			ok := s.sleepJitter(&jobCopy)
+			s.setWaiting(-1)
+			if !ok {
+				return
+			}
+			s.runJob(&jobCopy, config.TriggerSchedule)
+		}))
+		scheduled = true
+		next = sched.Next(time.Now())
+	}
+
+	var w *watcher.Watcher
+	if job.Trigger == config.TriggerWatch {
+		w = watcher.New(&jobCopy, func() {
+			if s.orchestrator.IsRunning(jobCopy.Name) {
+				// Changes landed mid-run; try again once they settle.
+				w.Poke()
+				return
+			}
+			s.runJob(&jobCopy, config.TriggerWatch)
+		})
+		if err := w.Start(); err != nil {
+			if scheduled {
+				s.cron.Remove(entryID)
+			}
+			return fmt.Errorf("watching sources: %w", err)
+		}
 	}
 
-	s.entries[job.Name] = entryID
-	slog.Info("scheduled job", "job", job.Name, "schedule", job.Schedule)
+	s.removeJobLocked(job.Name)
+	if scheduled {
+		s.entries[job.Name] = entryID
+		slog.Info("scheduled job",
+			"job", job.Name,
+			"schedule", job.Schedule,
+			"interval", job.Interval,
+			"timezone", job.Timezone,
+			"jitter", job.Jitter,
+			"stagger_offset", StaggerOffset(&jobCopy),
+			"next", next,
+		)
+	}
+	if w != nil {
+		s.watchers[job.Name] = w
+	}
+	s.jobs[job.Name] = job
 	return nil
 }
 
 func (s *Scheduler) RemoveJob(name string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.removeJobLocked(name)
+}
 
+func (s *Scheduler) removeJobLocked(name string) {
 	if id, ok := s.entries[name]; ok {
 		s.cron.Remove(id)
 		delete(s.entries, name)
 		slog.Info("unscheduled job", "job", name)
 	}
+	if w, ok := s.watchers[name]; ok {
+		w.Close()
+		delete(s.watchers, name)
+		slog.Info("stopped watching job", "job", name)
+	}
+	if t, ok := s.resumes[name]; ok {
+		t.Stop()
+		delete(s.resumes, name)
+	}
+	delete(s.jobs, name)
 }
 
 func (s *Scheduler) LoadFromConfig(cfg *config.Config) error {
+	s.trackConfig(cfg)
 	for _, job := range cfg.Jobs {
-		if job.Schedule == "" {
+		if !isAutomatic(&job) {
 			continue
 		}
 		if err := s.AddJob(job); err != nil {
@@ -71,29 +166,344 @@ func (s *Scheduler) LoadFromConfig(cfg *config.Config) error {
 	return nil
 }
 
+// Reload applies a new configuration in place. Only jobs that were added,
+// removed or changed are rescheduled; runs already in progress are left
+// alone and finish with the settings they started with.
+func (s *Scheduler) Reload(cfg *config.Config) (added, changed, removed int) {
+	s.trackConfig(cfg)
+
+	s.mu.Lock()
+	current := make(map[string]config.Job, len(s.jobs))
+	for name, job := range s.jobs {
+		current[name] = job
+	}
+	s.mu.Unlock()
+
+	wanted := make(map[string]config.Job)
+	for _, job := range cfg.Jobs {
+		if isAutomatic(&job) {
+			wanted[job.Name] = job
+		}
+	}
+
+	for name := range current {
+		if _, ok := wanted[name]; !ok {
+			s.RemoveJob(name)
+			removed++
+		}
+	}
+
+	for name, job := range wanted {
+		old, exists := current[name]
+		if exists && reflect.DeepEqual(old, job) {
+			continue
+		}
+		// AddJob swaps out the old entry only once the new one is in place.
+		if err := s.AddJob(job); err != nil {
+			slog.Error("failed to schedule job", "job", name, "error", err)
+			continue
+		}
+		if exists {
+			changed++
+		} else {
+			added++
+		}
+	}
+
+	slog.Info("scheduler reloaded", "added", added, "changed", changed, "removed", removed)
+	return added, changed, removed
+}
+
+func isAutomatic(job *config.Job) bool {
+	return job.HasSchedule() || job.Trigger == config.TriggerWatch
+}
+
 func (s *Scheduler) Start() {
+	s.started = time.Now()
+	s.cron.AddFunc("@daily", s.applyRetention)
 	s.cron.Start()
-	slog.Info("scheduler started", "jobs", len(s.entries))
+	go s.watchRPO()
+	go s.applyRetention()
+	slog.Info("scheduler started", "jobs", len(s.entries), "watched", len(s.watchers))
 }
 
-func (s *Scheduler) Stop() {
-	ctx := s.cron.Stop()
-	<-ctx.Done()
+// Shutdown stops scheduling new runs and waits up to timeout for running
+// jobs to finish. Jobs still running after that are cancelled and recorded
+// as interrupted.
+func (s *Scheduler) Shutdown(timeout time.Duration) {
+	s.mu.Lock()
+	s.stopping = true
+	close(s.done)
+	for name, w := range s.watchers {
+		w.Close()
+		delete(s.watchers, name)
+	}
+	for name, t := range s.resumes {
+		t.Stop()
+		delete(s.resumes, name)
+	}
+	s.mu.Unlock()
+
+	// cron's Stop context waits for running jobs; we drain them ourselves.
+	s.cron.Stop()
+
+	drained := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(timeout):
+		slog.Warn("shutdown deadline reached, interrupting running jobs", "timeout", timeout)
+		s.orchestrator.CancelAll(backup.NewAbortError(config.AbortInterrupted, "interrupted by daemon shutdown"))
+
+		select {
+		case <-drained:
+		case <-time.After(30 * time.Second):
+			slog.Error("running jobs did not stop after cancellation")
+		}
+	}
+
 	slog.Info("scheduler stopped")
 }
 
-func (s *Scheduler) runJob(job *config.Job) {
-	slog.Info("scheduler triggered job", "job", job.Name)
+// Running lists the jobs currently being executed.
+func (s *Scheduler) Running() []string {
+	return s.orchestrator.Running()
+}
+
+// SetNotifier sets where completed runs are announced and schedules the
+// dispatcher's digests. It may be called again on reload; runs finishing
+// afterwards use the new dispatcher.
+func (s *Scheduler) SetNotifier(d *notify.Dispatcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifier = d
+	for _, id := range s.digests {
+		s.cron.Remove(id)
+	}
+	s.digests = nil
+
+	for _, target := range d.DigestTargets() {
+		sched, err := cronParser.Parse(target.Digest.Schedule)
+		if err != nil {
+			slog.Error("invalid digest schedule", "target", target.Name, "error", err)
+			continue
+		}
+		name := target.Name
+		id := s.cron.Schedule(sched, cron.FuncJob(func() { s.sendDigest(d, name) }))
+		s.digests = append(s.digests, id)
+		slog.Info("scheduled digest", "target", name, "next", sched.Next(time.Now()))
+	}
+}
+
+func (s *Scheduler) sendDigest(d *notify.Dispatcher, target string) {
+	if err := d.SendDigest(context.Background(), target, s.store.LoadAll(), time.Now()); err != nil {
+		slog.Error("digest failed", "target", target, "error", err)
+		return
+	}
+	slog.Info("digest sent", "target", target)
+}
+
+// trackConfig records the jobs with a max_age and the history retention
+// policy. Manual jobs are included in both: a laptop job nobody remembers
+// to run is exactly what max_age is for.
+func (s *Scheduler) trackConfig(cfg *config.Config) {
+	var jobs []config.Job
+	names := make([]string, 0, len(cfg.Jobs))
+	for _, job := range cfg.Jobs {
+		names = append(names, job.Name)
+		if job.MaxAge > 0 {
+			jobs = append(jobs, job)
+		}
+	}
+	s.mu.Lock()
+	s.rpoJobs = jobs
+	s.retention = cfg.History
+	s.jobNames = names
+	s.mu.Unlock()
+}
+
+// applyRetention trims run history to the configured policy. It runs at
+// startup and then daily.
+func (s *Scheduler) applyRetention() {
+	s.mu.Lock()
+	policy := s.retention
+	jobs := s.jobNames
+	s.mu.Unlock()
+	if !policy.Enabled() {
+		return
+	}
+
+	results, err := s.store.ApplyRetention(policy, jobs, time.Now())
+	for _, r := range results {
+		slog.Info("history trimmed", "job", r.Job, "removed", r.Removed, "rolled_up", r.RolledUp)
+	}
+	if err != nil {
+		slog.Error("applying history retention", "error", err)
+	}
+}
+
+func (s *Scheduler) watchRPO() {
+	ticker := time.NewTicker(rpoCheckInterval)
+	defer ticker.Stop()
+
+	s.checkRPO(time.Now())
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.checkRPO(now)
+		}
+	}
+}
+
+// checkRPO alerts once for each job whose last success is older than its
+// max_age, and re-arms the alert when the job succeeds again. Paused jobs
+// are not alerted on, and a job that has never succeeded gets max_age from
+// daemon start before it counts as overdue.
+func (s *Scheduler) checkRPO(now time.Time) {
+	s.mu.Lock()
+	jobs := s.rpoJobs
+	notifier := s.notifier
+	s.mu.Unlock()
+	if len(jobs) == 0 {
+		return
+	}
+
+	pauses, err := s.pauses.Load()
+	if err != nil {
+		slog.Error("reading pause state", "error", err)
+	}
+
+	for _, job := range jobs {
+		rpo, _ := reporter.RPOFor(&job, s.store.LastSuccess(job.Name), now)
+		if !rpo.Stale() {
+			delete(s.stale, job.Name)
+			continue
+		}
+		if s.stale[job.Name] {
+			continue
+		}
+		if rpo.LastSuccess == nil && now.Sub(s.started) < job.MaxAge {
+			continue
+		}
+		if _, paused := pauses.Paused(job.Name, now); paused {
+			continue
+		}
+
+		s.stale[job.Name] = true
+		slog.Warn("job exceeded max age", "job", job.Name, "max_age", job.MaxAge, "age", rpo.Age)
+		notifier.Dispatch(context.Background(), notify.StaleEvent(job.Name, rpo, now))
+	}
+}
+
+// Subscribe registers fn to receive lifecycle events of scheduled runs.
+func (s *Scheduler) Subscribe(fn func(backup.Event)) {
+	s.orchestrator.Subscribe(fn)
+}
+
+// QueueDepth counts runs that have been triggered but not started yet:
+// scheduled runs waiting out their jitter and runs deferred to a window.
+func (s *Scheduler) QueueDepth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiting + len(s.resumes)
+}
+
+func (s *Scheduler) setWaiting(delta int) {
+	s.mu.Lock()
+	s.waiting += delta
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) runJob(job *config.Job, trigger string) {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return
+	}
+	s.inflight.Add(1)
+	s.mu.Unlock()
+	defer s.inflight.Done()
+
+	slog.Info("scheduler triggered job", "job", job.Name, "trigger", trigger)
+
+	if st, err := s.pauses.Load(); err != nil {
+		slog.Error("reading pause state", "error", err)
+	} else if e, paused := st.Paused(job.Name, time.Now()); paused {
+		slog.Info("skipping paused job", "job", job.Name, "until", e.Until)
+		return
+	}
+	if s.orchestrator.IsRunning(job.Name) {
+		slog.Warn("skipping scheduled job, previous run still going", "job", job.Name)
+		return
+	}
 
 	ctx := context.Background()
+
+	var resume time.Time
+	if job.Window != nil {
+		win, err := parseWindow(job.Window, job.Location())
+		if err != nil {
+			slog.Error("invalid run window", "job", job.Name, "error", err)
+			return
+		}
+
+		now := time.Now()
+		if !win.contains(now) {
+			s.resumeAt(job, win.nextOpen(now), config.TriggerCatchUp)
+			return
+		}
+
+		closes := win.closesAt(now)
+		resume = win.nextOpen(closes)
+		msg := fmt.Sprintf("run window closed — stopped, resuming at %s", resume.Format(time.DateTime))
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadlineCause(ctx, closes, backup.NewAbortError(config.AbortWindowClosed, msg))
+		defer cancel()
+	}
+
+	started := time.Now()
 	result, err := s.orchestrator.Run(ctx, job, false, nil)
 	if err != nil {
+		// A run that couldn't start, say on a bad destination, is still a
+		// failed run: history and alerts need to hear about it.
 		slog.Error("scheduled job failed", "job", job.Name, "error", err)
-		return
+		if result == nil {
+			result = &backend.Result{StartedAt: started, CompletedAt: time.Now()}
+		}
+		result.Success = false
+		result.Errors = append([]string{err.Error()}, result.Errors...)
 	}
 
 	record := reporter.ResultToRecord(job.Name, result, false)
-	s.store.Append(record)
+	record.Trigger = trigger
+	s.store.FlagAnomalies(job, &record)
+
+	switch result.AbortReason {
+	case config.AbortWindowClosed:
+		slog.Warn("run window closed, job stopped", "job", job.Name, "resume", resume)
+		s.resumeAt(job, resume, config.TriggerRetry)
+	case config.AbortTimeout, config.AbortStalled:
+		slog.Error("scheduled job timed out", "job", job.Name, "reason", result.AbortReason)
+	case config.AbortInterrupted:
+		slog.Warn("scheduled job interrupted by shutdown", "job", job.Name)
+	}
+
+	if err := s.store.Append(record); err != nil {
+		slog.Error("recording run", "job", job.Name, "error", err)
+	}
+
+	s.mu.Lock()
+	notifier := s.notifier
+	s.mu.Unlock()
+	notifier.DispatchRun(context.Background(), record)
 
 	if result.Success {
 		slog.Info("scheduled job completed",
@@ -107,4 +517,42 @@ func (s *Scheduler) runJob(job *config.Job) {
 			"errors", result.Errors,
 		)
 	}
+	for _, e := range result.TypedErrors {
+		slog.Warn("backup problem", "job", job.Name, "kind", e.Kind, "count", e.Count, "hint", backend.Hint(e))
+	}
+}
+
+// sleepJitter waits a random delay up to the job's jitter before a scheduled
+// run. It returns false if the scheduler is stopped while waiting.
+func (s *Scheduler) sleepJitter(job *config.Job) bool {
+	if job.Jitter <= 0 {
+		return true
+	}
+	delay := rand.N(job.Jitter)
+	slog.Debug("delaying scheduled job", "job", job.Name, "jitter", delay.Round(time.Second))
+
+	select {
+	case <-time.After(delay):
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// resumeAt starts the job once at the given time, replacing any pending
+// resume. It is used to continue runs deferred or cut off by a run window.
+func (s *Scheduler) resumeAt(job *config.Job, at time.Time, trigger string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.resumes[job.Name]; ok {
+		t.Stop()
+	}
+	s.resumes[job.Name] = time.AfterFunc(time.Until(at), func() {
+		s.mu.Lock()
+		delete(s.resumes, job.Name)
+		s.mu.Unlock()
+		s.runJob(job, trigger)
+	})
+	slog.Info("job deferred to next run window", "job", job.Name, "at", at)
 }
@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/notify"
+)
+
+func TestReload(t *testing.T) {
+	s := New()
+	defer s.Shutdown(time.Second)
+
+	cfg := &config.Config{Jobs: []config.Job{
+		{Name: "keep", Schedule: "0 2 * * *"},
+		{Name: "change", Schedule: "0 3 * * *"},
+		{Name: "drop", Schedule: "0 4 * * *"},
+		{Name: "manual"},
+	}}
+	if err := s.LoadFromConfig(cfg); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	keepID := s.entries["keep"]
+
+	next := &config.Config{Jobs: []config.Job{
+		{Name: "keep", Schedule: "0 2 * * *"},
+		{Name: "change", Schedule: "30 3 * * *"},
+		{Name: "new", Interval: time.Hour},
+		{Name: "manual"},
+	}}
+	added, changed, removed := s.Reload(next)
+	if added != 1 || changed != 1 || removed != 1 {
+		t.Errorf("Reload() = %d added, %d changed, %d removed; want 1, 1, 1", added, changed, removed)
+	}
+
+	if s.entries["keep"] != keepID {
+		t.Error("unchanged job was rescheduled")
+	}
+	if _, ok := s.entries["drop"]; ok {
+		t.Error("removed job still scheduled")
+	}
+	if _, ok := s.entries["manual"]; ok {
+		t.Error("job without schedule was scheduled")
+	}
+	if len(s.entries) != 3 {
+		t.Errorf("expected 3 cron entries, got %d", len(s.entries))
+	}
+}
+
+func TestReloadKeepsJobThatFailsToSchedule(t *testing.T) {
+	s := New()
+	defer s.Shutdown(time.Second)
+
+	if err := s.LoadFromConfig(&config.Config{Jobs: []config.Job{{Name: "nightly", Schedule: "0 2 * * *"}}}); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	id := s.entries["nightly"]
+
+	_, changed, _ := s.Reload(&config.Config{Jobs: []config.Job{{Name: "nightly", Schedule: "not a schedule"}}})
+	if changed != 0 {
+		t.Errorf("changed = %d, want 0", changed)
+	}
+	if s.entries["nightly"] != id || s.jobs["nightly"].Schedule != "0 2 * * *" {
+		t.Error("job with a bad new schedule lost its old entry")
+	}
+	if len(s.cron.Entries()) != 1 {
+		t.Errorf("expected 1 cron entry, got %d", len(s.cron.Entries()))
+	}
+}
+
+func TestCheckRPO(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var alerts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var evt notify.Event
+		json.NewDecoder(r.Body).Decode(&evt)
+		alerts = append(alerts, evt.Job+":"+evt.Outcome)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	cfg := &config.Config{
+		Notifications: config.Notifications{Targets: []config.NotifyTarget{
+			{Name: "hook", Type: config.NotifyWebhook, URL: srv.URL},
+		}},
+		Jobs: []config.Job{
+			{Name: "fresh", MaxAge: 24 * time.Hour},
+			{Name: "overdue", MaxAge: 24 * time.Hour},
+			{Name: "new", MaxAge: 24 * time.Hour},
+			{Name: "untracked"},
+		},
+	}
+
+	s := New()
+	s.SetNotifier(notify.NewDispatcher(cfg))
+	s.LoadFromConfig(cfg)
+	s.started = now.Add(-time.Hour)
+
+	s.store.Append(config.RunRecord{JobName: "fresh", CompletedAt: now.Add(-time.Hour), Success: true})
+	s.store.Append(config.RunRecord{JobName: "overdue", CompletedAt: now.Add(-48 * time.Hour), Success: true})
+	s.store.Append(config.RunRecord{JobName: "untracked", CompletedAt: now.Add(-480 * time.Hour), Success: true})
+
+	s.checkRPO(now)
+	s.checkRPO(now.Add(time.Minute))
+	if len(alerts) != 1 || alerts[0] != "overdue:stale" {
+		t.Fatalf("alerts = %v, want one stale alert for overdue", alerts)
+	}
+
+	// A job that never succeeded is only overdue max_age after daemon start.
+	s.checkRPO(now.Add(24 * time.Hour))
+	if len(alerts) != 3 || alerts[2] != "new:stale" {
+		t.Fatalf("alerts = %v, want fresh and new to turn stale", alerts)
+	}
+
+	// Succeeding clears the alert so the next lapse is reported again.
+	s.store.Append(config.RunRecord{JobName: "overdue", CompletedAt: now.Add(24 * time.Hour), Success: true})
+	s.checkRPO(now.Add(24 * time.Hour))
+	s.checkRPO(now.Add(72 * time.Hour))
+	if n := len(alerts); n != 4 || alerts[3] != "overdue:stale" {
+		t.Errorf("alerts = %v, want overdue alerted again", alerts)
+	}
+}
+
+func TestRunJobRecordsRunThatCannotStart(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var alerts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var evt notify.Event
+		json.NewDecoder(r.Body).Decode(&evt)
+		alerts = append(alerts, evt.Job+":"+evt.Outcome)
+	}))
+	defer srv.Close()
+
+	job := config.Job{Name: "web", Destination: config.Destination{Type: "tape"}}
+	cfg := &config.Config{
+		Notifications: config.Notifications{Targets: []config.NotifyTarget{
+			{Name: "hook", Type: config.NotifyWebhook, URL: srv.URL},
+		}},
+		Jobs: []config.Job{job},
+	}
+
+	s := New()
+	defer s.Shutdown(time.Second)
+	s.SetNotifier(notify.NewDispatcher(cfg))
+	s.runJob(&job, config.TriggerSchedule)
+
+	records := s.store.GetJobRecords("web", 0)
+	if len(records) != 1 || records[0].Success || records[0].Trigger != config.TriggerSchedule || len(records[0].Errors) == 0 {
+		t.Fatalf("records = %+v", records)
+	}
+	if records[0].StartedAt.IsZero() {
+		t.Error("failed run has no start time")
+	}
+	if len(alerts) != 1 || alerts[0] != "web:failure" {
+		t.Errorf("alerts = %v", alerts)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"charm.land/lipgloss/v2"
+
+	"github.com/klederson/keeper/internal/config"
 )
 
 func Logo() string {
@@ -61,7 +63,7 @@ func Table(columns []TableColumn, rows [][]string) string {
 		style := lipgloss.NewStyle().
 			Width(col.Width).
 			Foreground(Theme.Primary).
-			Bold(true).
+			Bold(!colorDisabled).
 			Padding(0, 1)
 		headerCells[i] = style.Render(col.Title)
 	}
@@ -124,3 +126,15 @@ func StatusIcon(success bool) string {
 	}
 	return ErrorStyle.Render("✗")
 }
+
+// OutcomeIcon renders a run outcome (success, warning or failure) as an icon.
+func OutcomeIcon(outcome string) string {
+	switch outcome {
+	case config.OutcomeSuccess:
+		return AccentStyle.Render("✓")
+	case config.OutcomeWarning:
+		return WarningStyle.Render("⚠")
+	default:
+		return ErrorStyle.Render("✗")
+	}
+}
@@ -9,18 +9,22 @@ import (
 	"charm.land/lipgloss/v2"
 
 	"github.com/klederson/keeper/internal/config"
+	"github.com/klederson/keeper/internal/pause"
 	"github.com/klederson/keeper/internal/reporter"
 )
 
 type DashboardModel struct {
-	cfg       *config.Config
-	store     *reporter.Store
-	stats     reporter.Stats
-	records   []config.RunRecord
-	cursor    int
-	width     int
-	height    int
-	quitting  bool
+	cfg      *config.Config
+	store    *reporter.Store
+	pauses   *pause.Store
+	paused   pause.State
+	stats    reporter.Stats
+	records  []config.RunRecord
+	cursor   int
+	detail   *reporter.JobStats // set while the job detail view is open
+	width    int
+	height   int
+	quitting bool
 }
 
 type tickMsg time.Time
@@ -31,14 +35,17 @@ func tickCmd() tea.Cmd {
 	})
 }
 
-func NewDashboard(cfg *config.Config, store *reporter.Store) DashboardModel {
+func NewDashboard(cfg *config.Config, store *reporter.Store, pauses *pause.Store) DashboardModel {
 	allRecords := store.LoadAll()
 	stats := reporter.CalculateStats(allRecords, time.Now().AddDate(0, 0, -30))
 	recent := store.GetRecentRecords(10)
+	paused, _ := pauses.Load()
 
 	return DashboardModel{
 		cfg:     cfg,
 		store:   store,
+		pauses:  pauses,
+		paused:  paused,
 		stats:   stats,
 		records: recent,
 	}
@@ -52,14 +59,33 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "q", "ctrl+c", "esc":
+		case "esc":
+			if m.detail != nil {
+				m.detail = nil
+				return m, nil
+			}
+			m.quitting = true
+			return m, tea.Quit
+		case "q", "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
+		case "backspace":
+			m.detail = nil
+		case "enter":
+			if m.detail == nil && m.cursor < len(m.cfg.Jobs) {
+				m.openDetail(m.cfg.Jobs[m.cursor].Name)
+			}
 		case "j", "down":
+			if m.detail != nil {
+				break
+			}
 			if m.cursor < len(m.cfg.Jobs)-1 {
 				m.cursor++
 			}
 		case "k", "up":
+			if m.detail != nil {
+				break
+			}
 			if m.cursor > 0 {
 				m.cursor--
 			}
@@ -81,6 +107,18 @@ func (m *DashboardModel) refreshData() {
 	allRecords := m.store.LoadAll()
 	m.stats = reporter.CalculateStats(allRecords, time.Now().AddDate(0, 0, -30))
 	m.records = m.store.GetRecentRecords(10)
+	if paused, err := m.pauses.Load(); err == nil {
+		m.paused = paused
+	}
+	if m.detail != nil {
+		m.openDetail(m.detail.Name)
+	}
+}
+
+// openDetail loads the 30-day stats for the job detail view.
+func (m *DashboardModel) openDetail(name string) {
+	js := reporter.CalculateJobStats(m.store, name, time.Now().AddDate(0, 0, -30))
+	m.detail = &js
 }
 
 func (m DashboardModel) View() tea.View {
@@ -103,8 +141,18 @@ func (m DashboardModel) View() tea.View {
 		Render(title)
 	b.WriteString(titleBar + "\n\n")
 
+	if m.detail != nil {
+		b.WriteString(renderJobDetail(*m.detail))
+		b.WriteString("\n")
+		b.WriteString(MutedStyle.Render("  [esc] back  [q] quit") + "\n")
+
+		v := tea.NewView(b.String())
+		v.AltScreen = true
+		return v
+	}
+
 	// Jobs table
-	b.WriteString(renderJobsTable(m.cfg, m.store, m.cursor))
+	b.WriteString(renderJobsTable(m.cfg, m.store, m.paused, m.cursor))
 	b.WriteString("\n")
 
 	// Stats panel
@@ -116,7 +164,7 @@ func (m DashboardModel) View() tea.View {
 	b.WriteString("\n")
 
 	// Footer
-	footer := MutedStyle.Render("  [↑/↓] navigate  [q] quit")
+	footer := MutedStyle.Render("  [↑/↓] navigate  [enter] details  [q] quit")
 	b.WriteString(footer + "\n")
 
 	v := tea.NewView(b.String())
@@ -124,7 +172,7 @@ func (m DashboardModel) View() tea.View {
 	return v
 }
 
-func renderJobsTable(cfg *config.Config, store *reporter.Store, cursor int) string {
+func renderJobsTable(cfg *config.Config, store *reporter.Store, paused pause.State, cursor int) string {
 	var b strings.Builder
 	b.WriteString(headerLine("Jobs") + "\n")
 
@@ -151,12 +199,18 @@ func renderJobsTable(cfg *config.Config, store *reporter.Store, cursor int) stri
 		if len(records) > 0 {
 			r := records[0]
 			lastRun = formatTimeAgo(r.CompletedAt)
-			if r.Success {
+			switch r.Status() {
+			case config.OutcomeSuccess:
 				status = AccentStyle.Render("✓ success")
-			} else {
+			case config.OutcomeWarning:
+				status = WarningStyle.Render("⚠ warning")
+			default:
 				status = ErrorStyle.Render("✗ failed")
 			}
 		}
+		if _, ok := paused.Paused(job.Name, time.Now()); ok {
+			status = WarningStyle.Render("⏸ paused")
+		}
 
 		prefix := "  "
 		nameStyle := TextStyle
@@ -184,11 +238,13 @@ func renderStatsPanel(stats reporter.Stats) string {
 
 	left := fmt.Sprintf("  Success rate: %s  │  Total: %s transferred",
 		AccentStyle.Render(fmt.Sprintf("%.1f%%", stats.SuccessRate)),
-		TextStyle.Render(formatBytesCompact(stats.TotalBytes)),
+		TextStyle.Render(reporter.FormatBytes(stats.TotalBytes)),
 	)
-	right := fmt.Sprintf("  Avg duration: %s │  Jobs run: %s",
+	right := fmt.Sprintf("  Avg duration: %s │  Jobs run: %s  │  Warnings: %s  │  Failed: %s",
 		TextStyle.Render(formatDurationCompact(stats.AvgDuration)),
 		TextStyle.Render(fmt.Sprintf("%d", stats.TotalRuns)),
+		WarningStyle.Render(fmt.Sprintf("%d", stats.WarningCount)),
+		ErrorStyle.Render(fmt.Sprintf("%d", stats.FailCount)),
 	)
 
 	b.WriteString(left + "\n")
@@ -197,6 +253,49 @@ func renderStatsPanel(stats reporter.Stats) string {
 	return b.String()
 }
 
+func renderJobDetail(js reporter.JobStats) string {
+	var b strings.Builder
+	b.WriteString(headerLine(fmt.Sprintf("%s — Stats (30 days)", js.Name)) + "\n")
+
+	if js.TotalRuns == 0 {
+		b.WriteString(MutedStyle.Render("  No runs in the last 30 days\n"))
+		return b.String()
+	}
+
+	lastSuccess := MutedStyle.Render("never")
+	if js.LastSuccess != nil {
+		lastSuccess = TextStyle.Render(formatTimeAgo(js.LastSuccess.CompletedAt))
+	}
+	streak := AccentStyle.Render("0")
+	if js.CurrentStreak > 0 {
+		streak = ErrorStyle.Render(fmt.Sprintf("%d", js.CurrentStreak))
+	}
+
+	b.WriteString(fmt.Sprintf("  Runs: %s  │  Success rate: %s  │  Warnings: %s  │  Failed: %s\n",
+		TextStyle.Render(fmt.Sprintf("%d", js.TotalRuns)),
+		AccentStyle.Render(fmt.Sprintf("%.1f%%", js.SuccessRate)),
+		WarningStyle.Render(fmt.Sprintf("%d", js.WarningCount)),
+		ErrorStyle.Render(fmt.Sprintf("%d", js.FailCount)),
+	))
+	b.WriteString(fmt.Sprintf("  Duration p50: %s  │  p95: %s  │  max: %s\n",
+		TextStyle.Render(formatDurationCompact(js.DurationP50)),
+		TextStyle.Render(formatDurationCompact(js.DurationP95)),
+		TextStyle.Render(formatDurationCompact(js.DurationMax)),
+	))
+	b.WriteString(fmt.Sprintf("  Throughput: %s  │  Change rate: %s per run  │  Transferred: %s\n",
+		TextStyle.Render(reporter.FormatBytes(int64(js.Throughput))+"/s"),
+		TextStyle.Render(fmt.Sprintf("%.1f%%", js.ChangeRate)),
+		TextStyle.Render(reporter.FormatBytes(js.TotalBytes)),
+	))
+	b.WriteString(fmt.Sprintf("  Last success: %s  │  Failure streak: %s  │  Longest: %s\n",
+		lastSuccess,
+		streak,
+		TextStyle.Render(fmt.Sprintf("%d", js.LongestStreak.Runs)),
+	))
+
+	return b.String()
+}
+
 func renderRecentActivity(records []config.RunRecord) string {
 	var b strings.Builder
 	b.WriteString(headerLine("Recent Activity") + "\n")
@@ -207,10 +306,7 @@ func renderRecentActivity(records []config.RunRecord) string {
 	}
 
 	for _, r := range records {
-		icon := AccentStyle.Render("✓")
-		if !r.Success {
-			icon = ErrorStyle.Render("✗")
-		}
+		icon := OutcomeIcon(r.Status())
 
 		timeStr := MutedStyle.Render(fmt.Sprintf("%-8s", formatTimeAgo(r.CompletedAt)))
 		name := SubtitleStyle.Render(fmt.Sprintf("[%s]", r.JobName))
@@ -218,7 +314,10 @@ func renderRecentActivity(records []config.RunRecord) string {
 		detail := ""
 		if r.Success {
 			dur := r.CompletedAt.Sub(r.StartedAt).Round(time.Second)
-			detail = TextStyle.Render(fmt.Sprintf("%s in %s", formatBytesCompact(r.BytesTransferred), dur))
+			detail = TextStyle.Render(fmt.Sprintf("%s in %s", reporter.FormatBytes(r.BytesTransferred), dur))
+			if len(r.Warnings) > 0 {
+				detail += WarningStyle.Render(fmt.Sprintf(" (%d warnings)", len(r.Warnings)))
+			}
 		} else if len(r.Errors) > 0 {
 			msg := r.Errors[0]
 			if len(msg) > 40 {
@@ -260,19 +359,6 @@ func formatTimeAgo(t time.Time) string {
 	}
 }
 
-func formatBytesCompact(b int64) string {
-	const unit = 1024
-	if b < unit {
-		return fmt.Sprintf("%d B", b)
-	}
-	div, exp := int64(unit), 0
-	for n := b / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
-}
-
 func formatDurationCompact(d time.Duration) string {
 	d = d.Round(time.Second)
 	if d < time.Minute {
@@ -104,9 +104,19 @@ func RunInitForm() (*config.Config, error) {
 
 	cfg := config.DefaultConfig()
 	cfg.LogLevel = logLevel
+	if destHost != "" || destUser != "" || destPath != "" {
+		cfg.Defaults.Destination = config.Destination{
+			Type:   "rsync",
+			Host:   destHost,
+			User:   destUser,
+			Path:   destPath,
+			SSHKey: sshKey,
+		}
+	}
 
 	fmt.Println()
 	fmt.Println(Success("Configuration initialized"))
+	fmt.Println(SubtitleStyle.Render("  Default destination for new jobs"))
 	fmt.Println(Label("  Host", destHost))
 	fmt.Println(Label("  User", destUser))
 	fmt.Println(Label("  Path", destPath))
@@ -117,7 +127,9 @@ func RunInitForm() (*config.Config, error) {
 	return &cfg, nil
 }
 
-func RunAddJobForm(existing *config.Job) (*config.Job, error) {
+// RunAddJobForm asks for a new job, prefilling the destination from the
+// configured defaults. Editing existing jobs is done by RunJobEditor.
+func RunAddJobForm(defaults config.Defaults) (*config.Job, error) {
 	job := config.Job{
 		Destination: config.Destination{
 			Type: "rsync",
@@ -125,17 +137,19 @@ func RunAddJobForm(existing *config.Job) (*config.Job, error) {
 		},
 		Compress: true,
 	}
-
-	if existing != nil {
-		job = *existing
-	}
-
-	title := "Add Backup Job"
-	if existing != nil {
-		title = "Edit Backup Job: " + existing.Name
+	if d := defaults.Destination; d != (config.Destination{}) {
+		job.Destination.Host = d.Host
+		job.Destination.User = d.User
+		job.Destination.SSHKey = d.SSHKey
+		if d.Type != "" {
+			job.Destination.Type = d.Type
+		}
+		if d.Port != 0 {
+			job.Destination.Port = d.Port
+		}
 	}
 
-	fmt.Println(Section(title))
+	fmt.Println(Section("Add Backup Job"))
 	fmt.Println(MutedStyle.Render("  Configure your backup job\n"))
 
 	// Basic info
@@ -158,7 +172,7 @@ func RunAddJobForm(existing *config.Job) (*config.Job, error) {
 
 	destHost := prompt("Host", job.Destination.Host, "backup.server.com")
 	destUser := prompt("User", job.Destination.User, "backupuser")
-	destPath := prompt("Path", job.Destination.Path, "/backups/my-project")
+	destPath := prompt("Path", defaults.JobPath(name), "/backups/my-project")
 	sshKey := prompt("SSH key path", job.Destination.SSHKey, "~/.ssh/id_rsa")
 
 	fmt.Println()
@@ -207,28 +221,26 @@ func RunAddJobForm(existing *config.Job) (*config.Job, error) {
 		bandwidth = "0"
 	}
 
-	result := &config.Job{
-		Name: name,
-		Sources: []config.Source{
-			{
-				Path:    sourcePath,
-				Exclude: excludeList,
-			},
-		},
-		Destination: config.Destination{
-			Type:   "rsync",
-			Host:   destHost,
-			User:   destUser,
-			Path:   destPath,
-			SSHKey: sshKey,
-			Port:   22,
-		},
-		Schedule:  schedule,
-		Bandwidth: bandwidth,
-		Compress:  compress,
+	// Start from the prefilled job so the default type and port are kept.
+	result := job.Clone()
+	result.Name = name
+	if len(result.Sources) == 0 {
+		result.Sources = []config.Source{{}}
+	}
+	result.Sources[0].Path = sourcePath
+	result.Sources[0].Exclude = excludeList
+	if result.Destination.Type == "" {
+		result.Destination.Type = "rsync"
 	}
+	result.Destination.Host = destHost
+	result.Destination.User = destUser
+	result.Destination.Path = destPath
+	result.Destination.SSHKey = sshKey
+	result.Schedule = schedule
+	result.Bandwidth = bandwidth
+	result.Compress = compress
 
-	return result, nil
+	return &result, nil
 }
 
 func ConfirmRemove(jobName string) (bool, error) {
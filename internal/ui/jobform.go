@@ -0,0 +1,728 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// ErrCancelled is returned by RunJobEditor when the user leaves without
+// saving.
+var ErrCancelled = errors.New("cancelled")
+
+type fieldKind int
+
+const (
+	fieldHeading fieldKind = iota
+	fieldText
+	fieldToggle
+)
+
+// formField is one row of the job editor. get renders the field's value
+// from the draft and set parses an edited value back into it. source is the
+// index of the source the row belongs to, or -1.
+type formField struct {
+	label  string
+	kind   fieldKind
+	source int
+	get    func(*config.Job) string
+	set    func(*config.Job, string) error
+}
+
+// accessor returns a pointer to a field of the job. Fields of optional
+// sections (window, ping, ...) return nil when reading a section that isn't
+// set; writing allocates it.
+type accessor[T any] func(j *config.Job, write bool) *T
+
+func field[T any](f func(*config.Job) *T) accessor[T] {
+	return func(j *config.Job, _ bool) *T { return f(j) }
+}
+
+func optional[S, T any](section func(*config.Job) **S, f func(*S) *T) accessor[T] {
+	return func(j *config.Job, write bool) *T {
+		s := section(j)
+		if *s == nil {
+			if !write {
+				return nil
+			}
+			*s = new(S)
+		}
+		return f(*s)
+	}
+}
+
+func heading(label string) formField {
+	return formField{label: label, kind: fieldHeading, source: -1}
+}
+
+func textField(label string, at accessor[string]) formField {
+	return formField{
+		label: label, kind: fieldText, source: -1,
+		get: func(j *config.Job) string {
+			if p := at(j, false); p != nil {
+				return *p
+			}
+			return ""
+		},
+		set: func(j *config.Job, v string) error {
+			*at(j, true) = strings.TrimSpace(v)
+			return nil
+		},
+	}
+}
+
+func intField(label string, at accessor[int]) formField {
+	return formField{
+		label: label, kind: fieldText, source: -1,
+		get: func(j *config.Job) string {
+			if p := at(j, false); p != nil && *p != 0 {
+				return strconv.Itoa(*p)
+			}
+			return ""
+		},
+		set: func(j *config.Job, v string) error {
+			n := 0
+			if v = strings.TrimSpace(v); v != "" {
+				var err error
+				if n, err = strconv.Atoi(v); err != nil {
+					return fmt.Errorf("%s must be a whole number", label)
+				}
+			}
+			*at(j, true) = n
+			return nil
+		},
+	}
+}
+
+func durationField(label string, at accessor[time.Duration]) formField {
+	return formField{
+		label: label, kind: fieldText, source: -1,
+		get: func(j *config.Job) string {
+			if p := at(j, false); p != nil && *p != 0 {
+				return formatDurationShort(*p)
+			}
+			return ""
+		},
+		set: func(j *config.Job, v string) error {
+			var d time.Duration
+			if v = strings.TrimSpace(v); v != "" {
+				var err error
+				if d, err = time.ParseDuration(v); err != nil {
+					return fmt.Errorf("%s must be a duration such as 30m or 4h", label)
+				}
+			}
+			*at(j, true) = d
+			return nil
+		},
+	}
+}
+
+func toggleField(label string, at accessor[bool]) formField {
+	return formField{
+		label: label, kind: fieldToggle, source: -1,
+		get: func(j *config.Job) string {
+			if p := at(j, false); p != nil && *p {
+				return "yes"
+			}
+			return "no"
+		},
+		set: func(j *config.Job, _ string) error {
+			p := at(j, true)
+			*p = !*p
+			return nil
+		},
+	}
+}
+
+// listField edits a list as comma-separated values.
+func listField(label string, at accessor[[]string]) formField {
+	return formField{
+		label: label, kind: fieldText, source: -1,
+		get: func(j *config.Job) string {
+			if p := at(j, false); p != nil {
+				return strings.Join(*p, ", ")
+			}
+			return ""
+		},
+		set: func(j *config.Job, v string) error {
+			*at(j, true) = splitList(v)
+			return nil
+		},
+	}
+}
+
+func intListField(label string, at accessor[[]int]) formField {
+	return formField{
+		label: label, kind: fieldText, source: -1,
+		get: func(j *config.Job) string {
+			p := at(j, false)
+			if p == nil {
+				return ""
+			}
+			parts := make([]string, len(*p))
+			for i, n := range *p {
+				parts[i] = strconv.Itoa(n)
+			}
+			return strings.Join(parts, ", ")
+		},
+		set: func(j *config.Job, v string) error {
+			var nums []int
+			for _, s := range splitList(v) {
+				n, err := strconv.Atoi(s)
+				if err != nil {
+					return fmt.Errorf("%s must be whole numbers", label)
+				}
+				nums = append(nums, n)
+			}
+			*at(j, true) = nums
+			return nil
+		},
+	}
+}
+
+func splitList(v string) []string {
+	var items []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			items = append(items, s)
+		}
+	}
+	return items
+}
+
+// formatDurationShort renders 1h30m0s as 1h30m.
+func formatDurationShort(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// jobFields lists every editable field of the job, including three rows
+// per source.
+func jobFields(j *config.Job) []formField {
+	window := func(j *config.Job) **config.Window { return &j.Window }
+	ping := func(j *config.Job) **config.Ping { return &j.Ping }
+	warnings := func(j *config.Job) **config.Warnings { return &j.Warnings }
+	anomaly := func(j *config.Job) **config.Anomaly { return &j.Anomaly }
+
+	fields := []formField{
+		heading("Job"),
+		textField("Name", field(func(j *config.Job) *string { return &j.Name })),
+		textField("Trigger (schedule/watch)", field(func(j *config.Job) *string { return &j.Trigger })),
+		heading("Sources"),
+	}
+
+	for i := range j.Sources {
+		src := func(j *config.Job) *config.Source { return &j.Sources[i] }
+		rows := []formField{
+			textField(fmt.Sprintf("Source %d", i+1), field(func(j *config.Job) *string { return &src(j).Path })),
+			listField("  Include", field(func(j *config.Job) *[]string { return &src(j).Include })),
+			listField("  Exclude", field(func(j *config.Job) *[]string { return &src(j).Exclude })),
+		}
+		for _, r := range rows {
+			r.source = i
+			fields = append(fields, r)
+		}
+	}
+
+	return append(fields,
+		heading("Destination"),
+		textField("Type", field(func(j *config.Job) *string { return &j.Destination.Type })),
+		textField("Host", field(func(j *config.Job) *string { return &j.Destination.Host })),
+		textField("User", field(func(j *config.Job) *string { return &j.Destination.User })),
+		textField("Path", field(func(j *config.Job) *string { return &j.Destination.Path })),
+		textField("SSH key", field(func(j *config.Job) *string { return &j.Destination.SSHKey })),
+		intField("Port", field(func(j *config.Job) *int { return &j.Destination.Port })),
+
+		heading("Schedule"),
+		textField("Cron schedule", field(func(j *config.Job) *string { return &j.Schedule })),
+		durationField("Interval", field(func(j *config.Job) *time.Duration { return &j.Interval })),
+		textField("Time zone", field(func(j *config.Job) *string { return &j.Timezone })),
+		textField("Window start", optional(window, func(w *config.Window) *string { return &w.Start })),
+		textField("Window end", optional(window, func(w *config.Window) *string { return &w.End })),
+		durationField("Jitter", field(func(j *config.Job) *time.Duration { return &j.Jitter })),
+		durationField("Stagger", field(func(j *config.Job) *time.Duration { return &j.Stagger })),
+		durationField("Watch debounce", field(func(j *config.Job) *time.Duration { return &j.Watch.Debounce })),
+		durationField("Watch min interval", field(func(j *config.Job) *time.Duration { return &j.Watch.MinInterval })),
+
+		heading("Transfer"),
+		textField("Bandwidth", field(func(j *config.Job) *string { return &j.Bandwidth })),
+		toggleField("Delete extraneous files", field(func(j *config.Job) *bool { return &j.Delete })),
+		toggleField("Compress", field(func(j *config.Job) *bool { return &j.Compress })),
+		durationField("Timeout", field(func(j *config.Job) *time.Duration { return &j.Timeout })),
+		durationField("Stall timeout", field(func(j *config.Job) *time.Duration { return &j.StallTimeout })),
+
+		heading("Monitoring"),
+		durationField("Max age (RPO)", field(func(j *config.Job) *time.Duration { return &j.MaxAge })),
+		textField("Ping URL", optional(ping, func(p *config.Ping) *string { return &p.URL })),
+		textField("Ping start URL", optional(ping, func(p *config.Ping) *string { return &p.Start })),
+		textField("Ping success URL", optional(ping, func(p *config.Ping) *string { return &p.Success })),
+		textField("Ping fail URL", optional(ping, func(p *config.Ping) *string { return &p.Fail })),
+		durationField("Ping timeout", optional(ping, func(p *config.Ping) *time.Duration { return &p.Timeout })),
+		intListField("Warning exit codes", optional(warnings, func(w *config.Warnings) *[]int { return &w.ExitCodes })),
+		listField("Warning patterns", optional(warnings, func(w *config.Warnings) *[]string { return &w.Patterns })),
+		toggleField("Anomaly detection off", optional(anomaly, func(a *config.Anomaly) *bool { return &a.Disabled })),
+		intField("Anomaly drop %", optional(anomaly, func(a *config.Anomaly) *int { return &a.Drop })),
+		intField("Anomaly spike %", optional(anomaly, func(a *config.Anomaly) *int { return &a.Spike })),
+	)
+}
+
+// normalizeJob drops optional sections the editor left empty, so touching
+// a field and clearing it again doesn't add an empty section.
+func normalizeJob(j *config.Job) {
+	if j.Window != nil && *j.Window == (config.Window{}) {
+		j.Window = nil
+	}
+	if j.Ping != nil && *j.Ping == (config.Ping{}) {
+		j.Ping = nil
+	}
+	if j.Warnings != nil && len(j.Warnings.ExitCodes) == 0 && len(j.Warnings.Patterns) == 0 {
+		j.Warnings = nil
+	}
+	if j.Anomaly != nil && *j.Anomaly == (config.Anomaly{}) {
+		j.Anomaly = nil
+	}
+}
+
+type editorState int
+
+const (
+	stateBrowse editorState = iota
+	stateEditing
+	stateReview
+)
+
+// JobEditorModel is the full-screen job editor. It works on a copy of the
+// job and only hands it back once the user has reviewed the diff.
+type JobEditorModel struct {
+	title    string
+	original config.Job
+	draft    *config.Job
+	validate func(*config.Job) error
+
+	state  editorState
+	cursor int
+	offset int
+	input  []rune
+	pos    int
+	err    string
+	diff   []diffLine
+
+	// quitArmed is set after esc with unsaved changes; a second esc quits.
+	quitArmed bool
+
+	width  int
+	height int
+	saved  bool
+}
+
+// NewJobEditor opens job for editing. validate checks the edited job in
+// the context of the whole config before the diff is shown.
+func NewJobEditor(title string, job config.Job, validate func(*config.Job) error) JobEditorModel {
+	draft := job.Clone()
+	m := JobEditorModel{
+		title:    title,
+		original: job,
+		draft:    &draft,
+		validate: validate,
+	}
+	m.cursor = m.nextField(0, 1)
+	return m
+}
+
+// RunJobEditor runs the editor and returns the edited job, or ErrCancelled.
+func RunJobEditor(title string, job config.Job, validate func(*config.Job) error) (*config.Job, error) {
+	final, err := tea.NewProgram(NewJobEditor(title, job, validate)).Run()
+	if err != nil {
+		return nil, err
+	}
+	m := final.(JobEditorModel)
+	if !m.saved {
+		return nil, ErrCancelled
+	}
+	return m.draft, nil
+}
+
+func (m JobEditorModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m JobEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.PasteMsg:
+		if m.state == stateEditing {
+			m.insert(strings.ReplaceAll(msg.Content, "\n", " "))
+		}
+	case tea.KeyPressMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		switch m.state {
+		case stateEditing:
+			m.updateEditing(msg)
+		case stateReview:
+			switch msg.String() {
+			case "y", "enter":
+				m.saved = true
+				return m, tea.Quit
+			case "n", "esc", "q":
+				m.state = stateBrowse
+			}
+		default:
+			return m.updateBrowse(msg)
+		}
+	}
+	m.scroll()
+	return m, nil
+}
+
+func (m JobEditorModel) updateBrowse(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	fields := jobFields(m.draft)
+	f := fields[m.cursor]
+	m.err = ""
+
+	key := msg.String()
+	if key == "esc" || key == "q" {
+		if m.quitArmed || !m.changed() {
+			return m, tea.Quit
+		}
+		m.quitArmed = true
+		m.err = "Unsaved changes — press esc again to discard them"
+		return m, nil
+	}
+	m.quitArmed = false
+
+	switch key {
+	case "up", "k":
+		m.cursor = m.nextField(m.cursor-1, -1)
+	case "down", "j":
+		m.cursor = m.nextField(m.cursor+1, 1)
+	case "enter", "space":
+		if f.kind == fieldToggle {
+			f.set(m.draft, "")
+		} else if f.kind == fieldText && msg.String() == "enter" {
+			m.startEditing(f.get(m.draft))
+		}
+	case "a":
+		m.draft.Sources = append(m.draft.Sources, config.Source{})
+		m.cursor = m.sourceRow(len(m.draft.Sources) - 1)
+		m.startEditing("")
+	case "x", "delete":
+		if i := f.source; i >= 0 {
+			m.draft.Sources = append(m.draft.Sources[:i], m.draft.Sources[i+1:]...)
+			m.cursor = m.nextField(min(m.cursor, len(jobFields(m.draft))-1), -1)
+		}
+	case "K", "shift+up":
+		if i := f.source; i > 0 {
+			s := m.draft.Sources
+			s[i-1], s[i] = s[i], s[i-1]
+			m.cursor -= 3
+		}
+	case "J", "shift+down":
+		if i := f.source; i >= 0 && i < len(m.draft.Sources)-1 {
+			s := m.draft.Sources
+			s[i+1], s[i] = s[i], s[i+1]
+			m.cursor += 3
+		}
+	case "ctrl+s", "s":
+		m.review()
+	}
+	m.scroll()
+	return m, nil
+}
+
+func (m *JobEditorModel) updateEditing(msg tea.KeyPressMsg) {
+	switch msg.String() {
+	case "enter":
+		if err := jobFields(m.draft)[m.cursor].set(m.draft, string(m.input)); err != nil {
+			m.err = err.Error()
+			return
+		}
+		m.state, m.err = stateBrowse, ""
+	case "esc":
+		m.state, m.err = stateBrowse, ""
+	case "left":
+		m.pos = max(0, m.pos-1)
+	case "right":
+		m.pos = min(len(m.input), m.pos+1)
+	case "home", "ctrl+a":
+		m.pos = 0
+	case "end", "ctrl+e":
+		m.pos = len(m.input)
+	case "backspace":
+		if m.pos > 0 {
+			m.input = append(m.input[:m.pos-1], m.input[m.pos:]...)
+			m.pos--
+		}
+	case "delete":
+		if m.pos < len(m.input) {
+			m.input = append(m.input[:m.pos], m.input[m.pos+1:]...)
+		}
+	case "ctrl+u":
+		m.input, m.pos = m.input[m.pos:], 0
+	default:
+		m.insert(msg.Text)
+	}
+}
+
+func (m *JobEditorModel) startEditing(value string) {
+	m.state = stateEditing
+	m.input = []rune(value)
+	m.pos = len(m.input)
+}
+
+func (m *JobEditorModel) insert(text string) {
+	if text == "" {
+		return
+	}
+	r := []rune(text)
+	m.input = append(m.input[:m.pos], append(r, m.input[m.pos:]...)...)
+	m.pos += len(r)
+}
+
+// review validates the draft and, if it changed anything, shows the diff.
+func (m *JobEditorModel) review() {
+	normalizeJob(m.draft)
+	if m.validate != nil {
+		if err := m.validate(m.draft); err != nil {
+			m.err = err.Error()
+			return
+		}
+	}
+	m.diff = diffJobs(m.baseline(), *m.draft)
+	if m.diff == nil {
+		m.err = "No changes to save"
+		return
+	}
+	m.state = stateReview
+}
+
+// baseline is the original job with empty sections dropped, so the diff
+// only shows real changes.
+func (m JobEditorModel) baseline() config.Job {
+	orig := m.original.Clone()
+	normalizeJob(&orig)
+	return orig
+}
+
+func (m JobEditorModel) changed() bool {
+	draft := m.draft.Clone()
+	normalizeJob(&draft)
+	return diffJobs(m.baseline(), draft) != nil
+}
+
+// nextField returns the first non-heading row from i in direction dir,
+// staying put at either end.
+func (m JobEditorModel) nextField(i, dir int) int {
+	fields := jobFields(m.draft)
+	for ; i >= 0 && i < len(fields); i += dir {
+		if fields[i].kind != fieldHeading {
+			return i
+		}
+	}
+	if dir < 0 {
+		return m.nextField(0, 1)
+	}
+	return min(m.cursor, len(fields)-1)
+}
+
+func (m JobEditorModel) sourceRow(source int) int {
+	for i, f := range jobFields(m.draft) {
+		if f.source == source {
+			return i
+		}
+	}
+	return m.cursor
+}
+
+// scroll keeps the cursor row inside the visible part of the form.
+func (m *JobEditorModel) scroll() {
+	rows := m.visibleRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+		if m.offset > 0 && jobFields(m.draft)[m.offset-1].kind == fieldHeading {
+			m.offset--
+		}
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+func (m JobEditorModel) visibleRows() int {
+	if m.height == 0 {
+		return 1 << 20
+	}
+	// Title, blank line, error line and the two footer lines.
+	return max(3, m.height-5)
+}
+
+func (m JobEditorModel) View() tea.View {
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render(" "+m.title+" ") + "\n\n")
+
+	if m.state == stateReview {
+		b.WriteString(headerLine("Review changes") + "\n")
+		for _, l := range m.diff {
+			switch l.op {
+			case '-':
+				b.WriteString(ErrorStyle.Render("  - "+l.text) + "\n")
+			case '+':
+				b.WriteString(AccentStyle.Render("  + "+l.text) + "\n")
+			default:
+				b.WriteString(MutedStyle.Render("    "+l.text) + "\n")
+			}
+		}
+		b.WriteString("\n" + WarningStyle.Render("  Save these changes? [y/n]") + "\n")
+		v := tea.NewView(b.String())
+		v.AltScreen = true
+		return v
+	}
+
+	fields := jobFields(m.draft)
+	labelWidth := 0
+	for _, f := range fields {
+		labelWidth = max(labelWidth, lipgloss.Width(f.label))
+	}
+
+	end := min(len(fields), m.offset+m.visibleRows())
+	for i := m.offset; i < end; i++ {
+		f := fields[i]
+		if f.kind == fieldHeading {
+			b.WriteString(headerLine(f.label) + "\n")
+			continue
+		}
+
+		prefix := "  "
+		label := SubtitleStyle.Render(fmt.Sprintf("%-*s", labelWidth, f.label))
+		value := f.get(m.draft)
+		if i == m.cursor {
+			prefix = AccentStyle.Render("▸ ")
+		}
+
+		switch {
+		case i == m.cursor && m.state == stateEditing:
+			before, after := string(m.input[:m.pos]), string(m.input[m.pos:])
+			value = TextStyle.Render(before) + AccentStyle.Render("█") + TextStyle.Render(after)
+		case value == "":
+			value = MutedStyle.Render("—")
+		case f.kind == fieldToggle && value == "yes":
+			value = AccentStyle.Render(value)
+		default:
+			value = TextStyle.Render(value)
+		}
+		b.WriteString(prefix + label + "  " + value + "\n")
+	}
+
+	if len(m.draft.Sources) == 0 {
+		b.WriteString(MutedStyle.Render("  No sources — press a to add one") + "\n")
+	}
+
+	b.WriteString("\n")
+	if m.err != "" {
+		b.WriteString(ErrorStyle.Render("  "+m.err) + "\n")
+	}
+	if m.state == stateEditing {
+		b.WriteString(MutedStyle.Render("  [enter] set  [esc] cancel  [ctrl+u] clear  lists are comma-separated") + "\n")
+	} else {
+		b.WriteString(MutedStyle.Render("  [↑/↓] move  [enter] edit  [space] toggle  [a] add source  [x] remove source  [K/J] reorder source  [s] review & save  [esc] quit") + "\n")
+	}
+
+	v := tea.NewView(b.String())
+	v.AltScreen = true
+	return v
+}
+
+// diffLine is one line of a diff: op is '-', '+' or ' '.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffJobs compares the YAML of two jobs, returning changed lines with one
+// line of context, or nil when they are the same.
+func diffJobs(before, after config.Job) []diffLine {
+	a, _ := yaml.Marshal(before)
+	b, _ := yaml.Marshal(after)
+	lines := diffLines(strings.Split(strings.TrimRight(string(a), "\n"), "\n"),
+		strings.Split(strings.TrimRight(string(b), "\n"), "\n"))
+
+	const context = 1
+	var out []diffLine
+	changed := false
+	for i, l := range lines {
+		near := false
+		for k := max(0, i-context); k <= min(len(lines)-1, i+context); k++ {
+			if lines[k].op != ' ' {
+				near = true
+			}
+		}
+		if !near {
+			continue
+		}
+		if l.op != ' ' {
+			changed = true
+		}
+		out = append(out, l)
+	}
+	if !changed {
+		return nil
+	}
+	return out
+}
+
+// diffLines is a longest-common-subsequence line diff.
+func diffLines(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, diffLine{'-', a[i]})
+			i++
+		default:
+			out = append(out, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		out = append(out, diffLine{'+', b[j]})
+	}
+	return out
+}
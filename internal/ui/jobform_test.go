@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+func fullJob() config.Job {
+	return config.Job{
+		Name: "docs",
+		Sources: []config.Source{
+			{Path: "/home/user/Documents", Include: []string{"**/*.md"}, Exclude: []string{"*.tmp", ".cache/"}},
+			{Path: "/home/user/Pictures"},
+		},
+		Destination:  config.Destination{Type: "rsync", Host: "nas.local", User: "backup", Path: "/backups/docs", SSHKey: "~/.ssh/nas", Port: 2222},
+		Schedule:     "0 2 * * *",
+		Timezone:     "Europe/Berlin",
+		Window:       &config.Window{Start: "22:00", End: "06:00"},
+		Jitter:       5 * time.Minute,
+		Stagger:      30 * time.Minute,
+		Bandwidth:    "500k",
+		Delete:       true,
+		Compress:     true,
+		Timeout:      4 * time.Hour,
+		StallTimeout: 90 * time.Minute,
+		MaxAge:       26 * time.Hour,
+		Ping:         &config.Ping{URL: "https://hc-ping.com/x", Timeout: 10 * time.Second},
+		Warnings:     &config.Warnings{ExitCodes: []int{24}, Patterns: []string{"^file has vanished"}},
+		Anomaly:      &config.Anomaly{Drop: 60, Spike: 200},
+		Trigger:      config.TriggerSchedule,
+		Watch:        config.Watch{Debounce: 30 * time.Second, MinInterval: 10 * time.Minute},
+	}
+}
+
+func TestJobFieldsRoundTrip(t *testing.T) {
+	job := fullJob()
+	draft := job.Clone()
+
+	// Writing back what every field shows must not change the job.
+	for _, f := range jobFields(&draft) {
+		if f.kind != fieldText {
+			continue
+		}
+		if err := f.set(&draft, f.get(&draft)); err != nil {
+			t.Fatalf("%s: %v", f.label, err)
+		}
+	}
+	normalizeJob(&draft)
+	if !reflect.DeepEqual(job, draft) {
+		t.Errorf("round trip changed the job:\n got %+v\nwant %+v", draft, job)
+	}
+	if d := diffJobs(job, draft); d != nil {
+		t.Errorf("diff of unchanged job = %v", d)
+	}
+}
+
+func press(m JobEditorModel, keys ...tea.KeyPressMsg) JobEditorModel {
+	for _, k := range keys {
+		next, _ := m.Update(k)
+		m = next.(JobEditorModel)
+	}
+	return m
+}
+
+func char(r rune) tea.KeyPressMsg {
+	return tea.KeyPressMsg{Code: r, Text: string(r)}
+}
+
+func TestJobEditorSources(t *testing.T) {
+	job := fullJob()
+	m := NewJobEditor("Edit", job, nil)
+
+	// Add a third source, then move it to the top.
+	m = press(m, char('a'), char('/'), char('s'), char('r'), char('v'), tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = press(m, char('K'), char('K'))
+	if got := m.draft.Sources; len(got) != 3 || got[0].Path != "/srv" || got[1].Path != "/home/user/Documents" {
+		t.Fatalf("sources = %+v", got)
+	}
+	if !reflect.DeepEqual(m.draft.Sources[1].Exclude, job.Sources[0].Exclude) {
+		t.Errorf("moved source lost its excludes: %+v", m.draft.Sources[1])
+	}
+
+	// Remove the last source and review.
+	m.cursor = m.sourceRow(2)
+	m = press(m, char('x'), char('s'))
+	if m.state != stateReview {
+		t.Fatalf("state = %v, err %q", m.state, m.err)
+	}
+	var added, removed int
+	for _, l := range m.diff {
+		switch l.op {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	if added == 0 || removed == 0 {
+		t.Errorf("diff = %+v", m.diff)
+	}
+
+	m = press(m, char('y'))
+	if !m.saved || m.draft.Destination.Port != 2222 || !m.draft.Delete || m.draft.Window == nil {
+		t.Errorf("saved=%v draft=%+v", m.saved, m.draft)
+	}
+	if len(job.Sources) != 2 {
+		t.Errorf("editor modified the original job: %+v", job.Sources)
+	}
+}
+
+func TestJobEditorNoChanges(t *testing.T) {
+	m := press(NewJobEditor("Edit", fullJob(), nil), char('s'))
+	if m.state == stateReview || m.err == "" {
+		t.Errorf("review with no changes: state=%v err=%q", m.state, m.err)
+	}
+}
@@ -76,3 +76,23 @@ var (
 			Border(lipgloss.NormalBorder(), false, false, true, false).
 			BorderForeground(Theme.Panel)
 )
+
+// colorDisabled is set by DisableColor.
+var colorDisabled bool
+
+// DisableColor strips colours and text attributes from every style, for
+// --no-color, NO_COLOR and structured output. Call it before rendering.
+func DisableColor() {
+	colorDisabled = true
+
+	none := lipgloss.NoColor{}
+	Theme.BG, Theme.Panel, Theme.Primary, Theme.Accent = none, none, none, none
+	Theme.Error, Theme.Warning, Theme.Text, Theme.Muted, Theme.Secondary = none, none, none, none, none
+
+	plain := lipgloss.NewStyle()
+	TitleStyle, SubtitleStyle, AccentStyle, ErrorStyle = plain, plain, plain, plain
+	WarningStyle, MutedStyle, TextStyle, SuccessBadge, ErrorBadge = plain, plain, plain, plain, plain
+	PanelStyle = plain.Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	ActivePanelStyle = PanelStyle
+	HeaderStyle = plain.Padding(0, 1).Border(lipgloss.NormalBorder(), false, false, true, false)
+}
@@ -0,0 +1,7 @@
+// Package version holds the keeper build version, set at link time:
+//
+//	go build -ldflags "-X github.com/klederson/keeper/internal/version.Version=v1.2.3"
+package version
+
+// Version is the keeper release this binary was built from.
+var Version = "dev"
@@ -0,0 +1,104 @@
+//go:build linux
+
+package watcher
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const watchMask = syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_CLOSE_WRITE |
+	syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+type inotifySource struct {
+	fd     int
+	file   *os.File
+	events chan event
+
+	mu    sync.Mutex
+	paths map[int32]string
+}
+
+func newEventSource() (eventSource, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %w", err)
+	}
+
+	s := &inotifySource{
+		fd: fd,
+		// A non-blocking fd lets the runtime poller unblock Read on Close.
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		events: make(chan event, 64),
+		paths:  make(map[int32]string),
+	}
+	go s.read()
+	return s, nil
+}
+
+func (s *inotifySource) Add(dir string) error {
+	wd, err := syscall.InotifyAddWatch(s.fd, dir, watchMask)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.paths[int32(wd)] = dir
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *inotifySource) Events() <-chan event {
+	return s.events
+}
+
+func (s *inotifySource) Close() error {
+	return s.file.Close()
+}
+
+func (s *inotifySource) read() {
+	defer close(s.events)
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := s.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameBytes := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+int(raw.Len)]
+			off += syscall.SizeofInotifyEvent + int(raw.Len)
+
+			if raw.Mask&syscall.IN_IGNORED != 0 {
+				s.mu.Lock()
+				delete(s.paths, raw.Wd)
+				s.mu.Unlock()
+				continue
+			}
+
+			s.mu.Lock()
+			dir, ok := s.paths[raw.Wd]
+			s.mu.Unlock()
+			if !ok {
+				continue
+			}
+
+			p := dir
+			if name := string(bytes.TrimRight(nameBytes, "\x00")); name != "" {
+				p = filepath.Join(dir, name)
+			}
+
+			s.events <- event{
+				path:    p,
+				isDir:   raw.Mask&syscall.IN_ISDIR != 0,
+				created: raw.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0,
+			}
+		}
+	}
+}
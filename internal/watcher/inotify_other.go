@@ -0,0 +1,9 @@
+//go:build !linux
+
+package watcher
+
+import "fmt"
+
+func newEventSource() (eventSource, error) {
+	return nil, fmt.Errorf("filesystem watch triggers are only supported on Linux")
+}
@@ -0,0 +1,234 @@
+package watcher
+
+import (
+	"io/fs"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+// Watcher triggers a job once changes under its sources have settled for the
+// debounce period, never more often than the job's minimum interval.
+type Watcher struct {
+	jobName     string
+	sources     []config.Source
+	debounce    time.Duration
+	minInterval time.Duration
+	onTrigger   func()
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	lastRun time.Time
+	closed  bool
+	events  eventSource
+	roots   map[string]*config.Source
+}
+
+// event is a single filesystem change reported by the platform event source.
+type event struct {
+	path    string
+	isDir   bool
+	created bool
+}
+
+type eventSource interface {
+	Add(dir string) error
+	Events() <-chan event
+	Close() error
+}
+
+func New(job *config.Job, onTrigger func()) *Watcher {
+	return &Watcher{
+		jobName:     job.Name,
+		sources:     job.Sources,
+		debounce:    job.WatchDebounce(),
+		minInterval: job.WatchMinInterval(),
+		onTrigger:   onTrigger,
+		roots:       make(map[string]*config.Source),
+	}
+}
+
+func (w *Watcher) Start() error {
+	src, err := newEventSource()
+	if err != nil {
+		return err
+	}
+	w.events = src
+
+	for i := range w.sources {
+		root := filepath.Clean(config.ExpandPath(w.sources[i].Path))
+		w.roots[root] = &w.sources[i]
+		w.addTree(root)
+	}
+
+	go w.loop()
+	slog.Info("watching sources", "job", w.jobName, "debounce", w.debounce, "min_interval", w.minInterval)
+	return nil
+}
+
+func (w *Watcher) Close() {
+	w.mu.Lock()
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	if w.events != nil {
+		w.events.Close()
+	}
+}
+
+// Poke re-arms the debounce timer as if a change had just been seen. The
+// scheduler uses it when a trigger fires while the job is still running.
+func (w *Watcher) Poke() {
+	w.arm(w.debounce)
+}
+
+func (w *Watcher) loop() {
+	for evt := range w.events.Events() {
+		root, src := w.sourceFor(evt.path)
+		if src == nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, evt.path)
+		if err != nil || Excluded(rel, evt.isDir, src.Include, src.Exclude) {
+			continue
+		}
+		if evt.created && evt.isDir {
+			w.addTree(evt.path)
+		}
+		slog.Debug("watch change", "job", w.jobName, "path", evt.path)
+		w.arm(w.debounce)
+	}
+}
+
+func (w *Watcher) arm(delay time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+	if w.timer == nil {
+		w.timer = time.AfterFunc(delay, w.fire)
+		return
+	}
+	w.timer.Reset(delay)
+}
+
+func (w *Watcher) fire() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	if wait := w.minInterval - time.Since(w.lastRun); !w.lastRun.IsZero() && wait > 0 {
+		w.timer.Reset(wait)
+		w.mu.Unlock()
+		return
+	}
+	w.lastRun = time.Now()
+	w.mu.Unlock()
+
+	slog.Info("watch triggered job", "job", w.jobName)
+	w.onTrigger()
+}
+
+// addTree registers dir and every non-excluded directory below it.
+func (w *Watcher) addTree(dir string) {
+	root, src := w.sourceFor(dir)
+	if src == nil {
+		return
+	}
+
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			slog.Warn("watch walk", "job", w.jobName, "path", p, "error", err)
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if rel, _ := filepath.Rel(root, p); rel != "." && Excluded(rel, true, src.Include, src.Exclude) {
+			return filepath.SkipDir
+		}
+		if err := w.events.Add(p); err != nil {
+			slog.Warn("watch add", "job", w.jobName, "path", p, "error", err)
+		}
+		return nil
+	})
+}
+
+func (w *Watcher) sourceFor(p string) (string, *config.Source) {
+	for root, src := range w.roots {
+		if p == root || strings.HasPrefix(p, root+string(filepath.Separator)) {
+			return root, src
+		}
+	}
+	return "", nil
+}
+
+// Excluded reports whether rel (relative to the source root) is filtered out
+// by the source's rsync-style patterns. Include patterns win over excludes,
+// matching rsync's first-match semantics for the common include/exclude
+// layout. Any excluded parent directory excludes its children too.
+func Excluded(rel string, isDir bool, include, exclude []string) bool {
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(rel, "/")
+
+	for i := range parts {
+		sub := strings.Join(parts[:i+1], "/")
+		dir := isDir || i < len(parts)-1
+		if matchAny(include, sub, dir) {
+			return false
+		}
+		if matchAny(exclude, sub, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if matchPattern(p, rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPattern(pattern, rel string, isDir bool) bool {
+	if strings.HasSuffix(pattern, "/") {
+		if !isDir {
+			return false
+		}
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	// Patterns without a slash match the final path component anywhere.
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+
+	if strings.HasPrefix(pattern, "**/") {
+		tail := strings.TrimPrefix(pattern, "**/")
+		parts := strings.Split(rel, "/")
+		for i := range parts {
+			if ok, _ := path.Match(tail, strings.Join(parts[i:], "/")); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	ok, _ := path.Match(strings.TrimPrefix(pattern, "/"), rel)
+	return ok
+}
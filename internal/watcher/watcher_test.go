@@ -0,0 +1,90 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klederson/keeper/internal/config"
+)
+
+func TestExcluded(t *testing.T) {
+	tests := []struct {
+		rel     string
+		isDir   bool
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{"src/main.go", false, nil, []string{"node_modules/"}, false},
+		{"node_modules", true, nil, []string{"node_modules/"}, true},
+		{"web/node_modules/lodash/index.js", false, nil, []string{"node_modules/"}, true},
+		{"node_modules", false, nil, []string{"node_modules/"}, false},
+		{"build/out.tmp", false, nil, []string{"*.tmp"}, true},
+		{"docs/readme.md", false, nil, []string{"/docs"}, true},
+		{"src/docs/readme.md", false, nil, []string{"/docs"}, false},
+		{"a/b/cache/x", false, nil, []string{"**/cache"}, true},
+		{"keep.tmp", false, []string{"keep.tmp"}, []string{"*.tmp"}, false},
+	}
+
+	for _, tt := range tests {
+		got := Excluded(tt.rel, tt.isDir, tt.include, tt.exclude)
+		if got != tt.want {
+			t.Errorf("Excluded(%q, dir=%v, inc=%v, exc=%v) = %v, want %v",
+				tt.rel, tt.isDir, tt.include, tt.exclude, got, tt.want)
+		}
+	}
+}
+
+func TestWatcherDebounce(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("inotify is Linux only")
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var fired atomic.Int32
+	w := New(&config.Job{
+		Name: "watch",
+		Sources: []config.Source{{
+			Path:    dir,
+			Exclude: []string{"node_modules/"},
+		}},
+		Watch: config.Watch{Debounce: 100 * time.Millisecond, MinInterval: time.Hour},
+	}, func() { fired.Add(1) })
+
+	if err := w.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer w.Close()
+
+	// Changes in excluded directories never trigger.
+	os.WriteFile(filepath.Join(dir, "node_modules", "pkg.js"), []byte("x"), 0644)
+	time.Sleep(300 * time.Millisecond)
+	if n := fired.Load(); n != 0 {
+		t.Fatalf("excluded change fired %d times", n)
+	}
+
+	// A burst of changes collapses into one run.
+	for i := 0; i < 5; i++ {
+		os.WriteFile(filepath.Join(dir, "file.txt"), []byte{byte(i)}, 0644)
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(400 * time.Millisecond)
+	if n := fired.Load(); n != 1 {
+		t.Fatalf("expected 1 trigger after burst, got %d", n)
+	}
+
+	// Further changes inside the minimum interval are held back.
+	os.WriteFile(filepath.Join(dir, "file.txt"), []byte("again"), 0644)
+	time.Sleep(300 * time.Millisecond)
+	if n := fired.Load(); n != 1 {
+		t.Fatalf("expected min interval to hold back run, got %d triggers", n)
+	}
+}